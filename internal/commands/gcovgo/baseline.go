@@ -0,0 +1,84 @@
+package gcovgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/pflag"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+)
+
+// baselineOptions 覆盖率基线选项
+type baselineOptions struct {
+	File      string
+	Tolerance float64
+	Update    bool
+}
+
+// AddFlags 将选项绑定到命令行参数
+func (o *baselineOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(
+		&o.File, "baseline", o.File,
+		"Exit with non-zero status if total or any file's line or branch coverage is lower than "+
+			"that in the specified baseline summary JSON file",
+	)
+	fs.Float64Var(
+		&o.Tolerance, "baseline-tolerance", o.Tolerance,
+		"Percentage points of coverage decrease allowed when comparing with the baseline",
+	)
+	fs.BoolVar(
+		&o.Update, "update-baseline", o.Update,
+		"Update the baseline file if coverage improves, keeping the higher coverage of the baseline and "+
+			"the current summary for each file (the baseline file is created if not exists)",
+	)
+}
+
+// Check 对比覆盖率与基线，覆盖率下降时返回列出所有下降情况的错误
+func (o *baselineOptions) Check(ctx context.Context, results []*gcov.CoverageInfo) error {
+	if o.File == "" {
+		return nil
+	}
+	logger := logr.FromContextOrDiscard(ctx)
+
+	root, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory error: %w", err)
+	}
+	current := gcov.Summarize(root, results...)
+
+	baseline, err := gcov.ReadSummaryReportFile(o.File)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if !o.Update {
+			logger.Info(fmt.Sprintf("WARN: baseline file %q not found, skip comparing", o.File))
+			return nil
+		}
+		logger.Info(fmt.Sprintf("baseline file %q not found, create it", o.File))
+		return gcov.WriteSummaryReportFile(o.File, current)
+	}
+
+	regressions, improved := gcov.CompareBaseline(baseline, current, o.Tolerance)
+	if len(regressions) > 0 {
+		msg := &strings.Builder{}
+		msg.WriteString("coverage is lower than baseline:")
+		for _, r := range regressions {
+			msg.WriteString("\n  ")
+			msg.WriteString(r.String())
+		}
+		return fmt.Errorf("%s", msg.String())
+	}
+
+	if o.Update && improved {
+		logger.Info(fmt.Sprintf("coverage improved, update baseline file %q", o.File))
+		return gcov.WriteSummaryReportFile(o.File, gcov.UpdateBaseline(baseline, current))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package gcovgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+)
+
+// newCompareCommand 创建 compare 子命令
+func newCompareCommand() *cobra.Command {
+	var before, after []string
+	outputFormat := "text"
+	inputOpts := &inputOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "compare --before INPUT... --after INPUT...",
+		Short: "Compare two coverage results and print gained and lost coverage",
+		Long: "Compare two coverage results and print gained and lost coverage.\n\n" +
+			"Each INPUT is a source file, an object file, a LCOV tracefile (.info), " +
+			"a build directory or a gcov JSON intermediate format file (.json or .json.gz).",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			root, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get working directory error: %w", err)
+			}
+			beforeResults, err := inputOpts.resolve(ctx, before)
+			if err != nil {
+				return err
+			}
+			afterResults, err := inputOpts.resolve(ctx, after)
+			if err != nil {
+				return err
+			}
+			c := gcov.Compare(root, beforeResults, afterResults)
+
+			switch outputFormat {
+			case "text":
+				_, err = fmt.Fprint(os.Stdout, c.Text())
+			case "json":
+				var raw []byte
+				raw, err = json.MarshalIndent(c, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshal comparison to json error: %w", err)
+				}
+				_, err = fmt.Fprintln(os.Stdout, string(raw))
+			default:
+				return fmt.Errorf("unknown output format: %q", outputFormat)
+			}
+			if err != nil {
+				return fmt.Errorf("write output error: %w", err)
+			}
+			return nil
+		},
+	}
+
+	// 绑定选项到命令行参数
+	fs := cmd.Flags()
+	fs.StringArrayVar(&before, "before", before, "Inputs of the coverage result before, can be specified multiple times")
+	fs.StringArrayVar(&after, "after", after, "Inputs of the coverage result after, can be specified multiple times")
+	fs.StringVarP(&outputFormat, "format", "f", outputFormat, "Output format, one of (text, json)")
+	inputOpts.AddFlags(fs)
+	_ = cmd.MarkFlagRequired("before")
+	_ = cmd.MarkFlagRequired("after")
+
+	return cmd
+}
@@ -0,0 +1,110 @@
+package gcovgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yhlooo/gcovgo/pkg/diffcover"
+)
+
+// newDiffCoverCommand 创建 diff-cover 子命令
+func newDiffCoverCommand() *cobra.Command {
+	patchFile := ""
+	strip := 1
+	outputFormat := "text"
+	outputFile := ""
+	failUnder := float64(0)
+	inputOpts := &inputOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "diff-cover --patch PATCH {SOURCE|OBJ|BUILD_DIR|TRACEFILE.info|JSON}...",
+		Short: "Print coverage of lines changed by a unified diff patch",
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			root, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get working directory error: %w", err)
+			}
+
+			// 解析补丁
+			var changes []diffcover.ChangedFile
+			if patchFile == "-" {
+				changes, err = diffcover.ParsePatch(os.Stdin, strip)
+			} else {
+				f, openErr := os.Open(patchFile)
+				if openErr != nil {
+					return fmt.Errorf("open patch file %q error: %w", patchFile, openErr)
+				}
+				changes, err = diffcover.ParsePatch(f, strip)
+				_ = f.Close()
+			}
+			if err != nil {
+				return fmt.Errorf("parse patch %q error: %w", patchFile, err)
+			}
+
+			results, err := inputOpts.Resolve(ctx, args)
+			if err != nil {
+				return err
+			}
+			report := diffcover.Compute(ctx, changes, root, results...)
+
+			// 打开输出文件
+			w := os.Stdout
+			if outputFile != "" {
+				w, err = os.OpenFile(outputFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+				if err != nil {
+					return fmt.Errorf("open output file %q error: %w", outputFile, err)
+				}
+				defer func() { _ = w.Close() }()
+			}
+
+			var outputContent string
+			switch outputFormat {
+			case "text":
+				outputContent = report.Text()
+			case "markdown":
+				outputContent = report.Markdown()
+			case "json":
+				raw, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshal report to json error: %w", err)
+				}
+				outputContent = string(raw) + "\n"
+			default:
+				return fmt.Errorf("unknown output format: %q", outputFormat)
+			}
+			if _, err := fmt.Fprint(w, outputContent); err != nil {
+				return fmt.Errorf("write output error: %w", err)
+			}
+
+			if percent := report.Percent(); percent < failUnder {
+				return fmt.Errorf("diff coverage %.2f%% is below %.2f%%", percent, failUnder)
+			}
+			return nil
+		},
+	}
+
+	// 绑定选项到命令行参数
+	fs := cmd.Flags()
+	fs.StringVar(&patchFile, "patch", patchFile, "Unified diff patch file (e.g. output of git diff), - for stdin")
+	fs.IntVarP(
+		&strip, "strip", "p", strip,
+		"Number of leading path components to strip from file names in the patch, like patch -p",
+	)
+	fs.StringVarP(&outputFormat, "format", "f", outputFormat, "Output format, one of (text, json, markdown)")
+	fs.StringVarP(&outputFile, "output", "o", outputFile, "Write output to file instead of stdout")
+	fs.Float64Var(
+		&failUnder, "fail-under", failUnder,
+		"Exit with non-zero status if coverage of changed lines is below the percent",
+	)
+	inputOpts.AddFlags(fs)
+	inputOpts.AddRootFlags(fs)
+	_ = cmd.MarkFlagRequired("patch")
+
+	return cmd
+}
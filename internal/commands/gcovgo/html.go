@@ -0,0 +1,52 @@
+package gcovgo
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yhlooo/gcovgo/pkg/htmlreport"
+)
+
+// newHTMLCommand 创建 html 子命令
+func newHTMLCommand() *cobra.Command {
+	outputDir := ""
+	title := ""
+	inputOpts := &inputOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "html -o DIR {SOURCE|OBJ|BUILD_DIR|TRACEFILE.info|JSON}...",
+		Short: "Generate static HTML coverage report",
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			root, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get working directory error: %w", err)
+			}
+
+			results, err := inputOpts.Resolve(ctx, args)
+			if err != nil {
+				return err
+			}
+			return htmlreport.Generate(ctx, outputDir, htmlreport.Options{
+				Title:     title,
+				Root:      root,
+				Timestamp: time.Now(),
+			}, results...)
+		},
+	}
+
+	// 绑定选项到命令行参数
+	fs := cmd.Flags()
+	fs.StringVarP(&outputDir, "output", "o", outputDir, "Write report to the directory")
+	fs.StringVar(&title, "title", title, "Title of the report")
+	inputOpts.AddFlags(fs)
+	inputOpts.AddRootFlags(fs)
+	_ = cmd.MarkFlagRequired("output")
+
+	return cmd
+}
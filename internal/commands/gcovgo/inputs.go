@@ -0,0 +1,396 @@
+package gcovgo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/pflag"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+	"github.com/yhlooo/gcovgo/pkg/lcov"
+)
+
+// inputOptions 输入解析选项
+type inputOptions struct {
+	// 递归查找 gcov note 文件的构建目录
+	Roots []string
+	// gcov 二进制解析选项
+	ResolveOptions gcov.ResolveOptions
+	// 是否按源文件合并所有结果
+	Merge bool
+	// 同时解析的 gcov 二进制文件数，不大于 0 时为 CPU 数
+	Jobs int
+	// 包含的源文件路径模式
+	Include []string
+	// 排除的源文件路径模式
+	Exclude []string
+	// 函数过滤器
+	FunctionFilters gcov.FunctionFilters
+	// 源文件中排除标记的前缀
+	ExclusionMarkerPrefixes []string
+	// 是否忽略源文件中的排除标记
+	NoExclusionMarkers bool
+	// 分支排除选项
+	BranchExclusionOptions gcov.BranchExclusionOptions
+	// FROM=TO 格式的路径映射规则
+	PathMappings []string
+	// 源文件路径改写选项
+	PathOptions gcov.PathOptions
+	// gcov data 文件查找选项
+	DataFileOptions gcov.DataFileOptions
+}
+
+// AddFlags 将选项绑定到命令行参数
+func (o *inputOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(
+		&o.ResolveOptions.IncludeArtificial, "include-artificial", o.ResolveOptions.IncludeArtificial,
+		"Include compiler-generated functions (e.g. static initializers) and their lines",
+	)
+	fs.BoolVar(
+		&o.Merge, "merge", o.Merge,
+		"Merge all inputs into a single result, combining coverage of the same source file "+
+			"(e.g. a header included by many objects)",
+	)
+	fs.IntVarP(
+		&o.Jobs, "jobs", "j", o.Jobs,
+		"Number of objects to resolve in parallel, defaults to the number of CPUs",
+	)
+	fs.StringArrayVar(
+		&o.Include, "include", o.Include,
+		"Only include source files matching the pattern, can be specified multiple times. "+
+			"Patterns are globs (e.g. 'src/**/*.c') or regular expressions prefixed with 'regex:', "+
+			"matched against paths relative to the working directory (absolute paths for sources outside it)",
+	)
+	fs.StringArrayVar(
+		&o.Exclude, "exclude", o.Exclude,
+		"Exclude source files matching the pattern (e.g. '/usr/include/**', '**/third_party/**'), "+
+			"can be specified multiple times, takes precedence over --include",
+	)
+	fs.Var(
+		&functionFilterValue{filters: &o.FunctionFilters}, "include-function",
+		"Only include functions whose mangled or demangled name matches the regular expression, "+
+			"can be specified multiple times. Lines of removed functions are removed too. "+
+			"Same as gcov's --include of gcc 12, filters apply in order and the last matching one wins",
+	)
+	fs.Var(
+		&functionFilterValue{filters: &o.FunctionFilters, exclude: true}, "exclude-function",
+		"Exclude functions whose mangled or demangled name matches the regular expression, "+
+			"can be specified multiple times. Same as gcov's --exclude of gcc 12",
+	)
+	fs.StringArrayVar(
+		&o.ExclusionMarkerPrefixes, "exclusion-marker-prefix", gcov.DefaultExclusionMarkerPrefixes,
+		"Prefix of exclusion markers in source files, can be specified multiple times. "+
+			"PREFIX_LINE and PREFIX_START/PREFIX_STOP exclude lines, "+
+			"PREFIX_BR_LINE and PREFIX_BR_START/PREFIX_BR_STOP exclude branches",
+	)
+	fs.BoolVar(
+		&o.NoExclusionMarkers, "no-exclusion-markers", o.NoExclusionMarkers,
+		"Ignore exclusion markers in source files",
+	)
+	fs.BoolVar(
+		&o.BranchExclusionOptions.Throw, "exclude-throw-branches", o.BranchExclusionOptions.Throw,
+		"Exclude branches taken only when an exception is thrown",
+	)
+	fs.BoolVar(
+		&o.BranchExclusionOptions.Unreachable, "exclude-unreachable-branches", o.BranchExclusionOptions.Unreachable,
+		"Exclude branches on lines containing only braces, whitespace and comments "+
+			"(e.g. compiler-generated destructor calls at the end of a scope)",
+	)
+	fs.StringArrayVar(
+		&o.PathMappings, "path-map", o.PathMappings,
+		"Map source paths under FROM to TO, in format FROM=TO, can be specified multiple times "+
+			"and the first matching one is used. Applies to output file names and reading source files",
+	)
+	fs.StringVarP(
+		&o.PathOptions.SourcePrefix, "source-prefix", "s", o.PathOptions.SourcePrefix,
+		"Remove the directory prefix from source paths (after --path-map), like gcov's -s",
+	)
+	fs.BoolVarP(
+		&o.PathOptions.RelativeOnly, "relative-only", "r", o.PathOptions.RelativeOnly,
+		"Only include source files with relative paths (after --source-prefix), like gcov's -r",
+	)
+	fs.StringVar(
+		&o.DataFileOptions.ObjectDirectory, "object-directory", o.DataFileOptions.ObjectDirectory,
+		"Directory to find .gcda files in, like gcov's -o. .gcda files of .gcno files found in --root or "+
+			"directory inputs keep their paths relative to the build directory, others are found by file name. "+
+			"Can not be used with --gcov-prefix",
+	)
+	fs.StringVar(
+		&o.DataFileOptions.Prefix, "gcov-prefix", o.DataFileOptions.Prefix,
+		"Find .gcda files under the directory, same as GCOV_PREFIX set when running the tests",
+	)
+	fs.IntVar(
+		&o.DataFileOptions.PrefixStrip, "gcov-prefix-strip", o.DataFileOptions.PrefixStrip,
+		"Number of leading directories removed from .gcda file paths, "+
+			"same as GCOV_PREFIX_STRIP set when running the tests, only works with --gcov-prefix",
+	)
+}
+
+// AddRootFlags 将构建目录选项绑定到命令行参数
+func (o *inputOptions) AddRootFlags(fs *pflag.FlagSet) {
+	fs.StringArrayVar(
+		&o.Roots, "root", o.Roots,
+		"Build directory to search for .gcno files recursively, can be specified multiple times. "+
+			"Objects without .gcda file are included as zero coverage",
+	)
+}
+
+// Resolve 解析输入和 --root 指定的构建目录对应的覆盖情况信息，指定 --merge 时合并为一个结果
+//
+// 输入可以是源文件、目标文件、 gcov note 或 data 文件、构建目录、 LCOV 跟踪文件或 gcov JSON 中间格式文件。
+// 解析失败的输入会被记录日志并跳过
+func (o *inputOptions) Resolve(ctx context.Context, args []string) ([]*gcov.CoverageInfo, error) {
+	inputs := append(append([]string{}, args...), o.Roots...)
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no input specified")
+	}
+	return o.resolve(ctx, inputs)
+}
+
+// resolve 解析输入对应的覆盖情况信息，指定 --merge 时合并为一个结果
+func (o *inputOptions) resolve(ctx context.Context, inputs []string) ([]*gcov.CoverageInfo, error) {
+	filter, err := o.fileFilter()
+	if err != nil {
+		return nil, err
+	}
+	pathOpts, err := o.pathOptions()
+	if err != nil {
+		return nil, err
+	}
+	if err = o.DataFileOptions.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid --object-directory and --gcov-prefix: %w", err)
+	}
+
+	results, err := o.resolveInputs(ctx, inputs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		if !pathOpts.Empty() {
+			results[i] = results[i].RewritePaths(pathOpts)
+		}
+		if !filter.Empty() {
+			results[i] = results[i].Filter(filter)
+		}
+	}
+	if !o.NoExclusionMarkers {
+		results = gcov.ExcludeMarked(ctx, o.ExclusionMarkerPrefixes, results...)
+	}
+	if o.BranchExclusionOptions.Throw || o.BranchExclusionOptions.Unreachable {
+		results = gcov.ExcludeBranches(ctx, o.BranchExclusionOptions, results...)
+	}
+	for i := range results {
+		results[i] = results[i].FilterFunctions(o.FunctionFilters)
+	}
+	if !o.Merge || len(results) == 0 {
+		return results, nil
+	}
+	return []*gcov.CoverageInfo{gcov.Merge(results...)}, nil
+}
+
+// functionFilterValue 函数过滤器命令行参数值
+//
+// --include-function 和 --exclude-function 追加到同一列表，以保留过滤器的顺序
+type functionFilterValue struct {
+	filters *gcov.FunctionFilters
+	exclude bool
+}
+
+var _ pflag.Value = &functionFilterValue{}
+
+// String 返回参数值的文本形式
+func (v *functionFilterValue) String() string {
+	var patterns []string
+	for _, filter := range *v.filters {
+		if filter.Exclude == v.exclude {
+			patterns = append(patterns, filter.Regexp.String())
+		}
+	}
+	if len(patterns) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(patterns, ",") + "]"
+}
+
+// Set 设置参数值
+func (v *functionFilterValue) Set(s string) error {
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return fmt.Errorf("invalid function filter %q: %w", s, err)
+	}
+	*v.filters = append(*v.filters, gcov.FunctionFilter{Exclude: v.exclude, Regexp: re})
+	return nil
+}
+
+// Type 返回参数值类型
+func (v *functionFilterValue) Type() string {
+	return "regex"
+}
+
+// pathOptions 返回源文件路径改写选项
+func (o *inputOptions) pathOptions() (*gcov.PathOptions, error) {
+	ret := o.PathOptions
+	ret.Mappings = nil
+	for _, s := range o.PathMappings {
+		m, err := gcov.ParsePathMapping(s)
+		if err != nil {
+			return nil, err
+		}
+		ret.Mappings = append(ret.Mappings, m)
+	}
+	return &ret, nil
+}
+
+// fileFilter 返回 --include 和 --exclude 对应的源文件过滤器
+func (o *inputOptions) fileFilter() (*gcov.FileFilter, error) {
+	ret := &gcov.FileFilter{}
+	for _, item := range []struct {
+		patterns []string
+		dst      *[]gcov.PathPattern
+	}{
+		{o.Include, &ret.Include},
+		{o.Exclude, &ret.Exclude},
+	} {
+		for _, s := range item.patterns {
+			p, err := gcov.ParsePathPattern(s)
+			if err != nil {
+				return nil, err
+			}
+			*item.dst = append(*item.dst, p)
+		}
+	}
+	if ret.Empty() {
+		return ret, nil
+	}
+
+	root, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("get working directory error: %w", err)
+	}
+	ret.Root = root
+	return ret, nil
+}
+
+// resolveInputs 解析输入对应的覆盖情况信息，最多同时解析 o.Jobs 个 gcov 二进制文件，结果顺序与输入顺序一致
+//
+// 解析失败的输入会被记录日志并跳过，上下文被取消或多个 note 文件对应同一 data 文件时返回错误
+func (o *inputOptions) resolveInputs(ctx context.Context, args []string) ([]*gcov.CoverageInfo, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	// 按输入顺序记录解析结果， binaryFile 不小于 0 时表示对应 binaryFiles 中的 gcov 二进制文件
+	type entry struct {
+		results    []*gcov.CoverageInfo
+		binaryFile int
+	}
+	var entries []entry
+	var binaryFiles []gcov.BinaryFile
+	resolvedNoteFiles := map[string]bool{}
+	// data 文件对应的 note 文件，用于检查多个 note 文件对应同一 data 文件的情况
+	dataFileNotes := map[string]string{}
+	// buildDir 为 note 文件所在的构建目录，直接输入的 note 文件为空
+	addNoteFile := func(noteFileName, buildDir string) error {
+		key := filepath.Clean(noteFileName)
+		if abs, err := filepath.Abs(noteFileName); err == nil {
+			key = abs
+		}
+		if resolvedNoteFiles[key] {
+			return nil
+		}
+		resolvedNoteFiles[key] = true
+
+		opts := o.DataFileOptions
+		opts.BuildDirectory = buildDir
+		dataFileName := gcov.LocateDataFile(noteFileName, opts)
+		if _, err := os.Stat(dataFileName); err != nil {
+			if !os.IsNotExist(err) {
+				logger.Error(err, fmt.Sprintf("get data file %q info error", dataFileName))
+				return nil
+			}
+			// data 文件不存在时视为未执行
+			dataFileName = ""
+		}
+		if dataFileName != "" {
+			// 比如 --object-directory 下按文件名查找时不同目录中的同名 note 文件
+			if other, ok := dataFileNotes[filepath.Clean(dataFileName)]; ok {
+				return fmt.Errorf(
+					"note files %q and %q correspond to the same data file %q",
+					other, noteFileName, dataFileName,
+				)
+			}
+			dataFileNotes[filepath.Clean(dataFileName)] = noteFileName
+		}
+		entries = append(entries, entry{binaryFile: len(binaryFiles)})
+		binaryFiles = append(binaryFiles, gcov.BinaryFile{NoteFile: noteFileName, DataFile: dataFileName})
+		return nil
+	}
+
+	for _, fileName := range args {
+		if info, err := os.Stat(fileName); err == nil && info.IsDir() {
+			// 构建目录
+			noteFiles, err := gcov.FindNoteFiles(fileName)
+			if err != nil {
+				logger.Error(err, fmt.Sprintf("find note files in %q error", fileName))
+				continue
+			}
+			if len(noteFiles) == 0 {
+				logger.Info(fmt.Sprintf("WARN: no note file found in %q", fileName))
+			}
+			for _, noteFileName := range noteFiles {
+				if err := addNoteFile(noteFileName, fileName); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		switch filepath.Ext(fileName) {
+		case ".info":
+			// LCOV 跟踪文件
+			ret, err := lcov.ParseFile(fileName)
+			if err != nil {
+				logger.Error(err, fmt.Sprintf("parse %q error", fileName))
+				continue
+			}
+			entries = append(entries, entry{results: []*gcov.CoverageInfo{ret}, binaryFile: -1})
+		case ".json", ".gz":
+			// gcov JSON 中间格式文件
+			ret, err := gcov.ParseJSONFile(fileName)
+			if err != nil {
+				logger.Error(err, fmt.Sprintf("parse %q error", fileName))
+				continue
+			}
+			entries = append(entries, entry{results: ret, binaryFile: -1})
+		default:
+			// 源文件、目标文件或 gcov note 、 data 文件
+			if err := addNoteFile(strings.TrimSuffix(fileName, filepath.Ext(fileName))+gcov.NoteFileExt, ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	resolved, err := gcov.ResolveBinaryFiles(ctx, binaryFiles, o.ResolveOptions, o.Jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*gcov.CoverageInfo
+	for _, e := range entries {
+		if e.binaryFile < 0 {
+			results = append(results, e.results...)
+			continue
+		}
+		ret := resolved[e.binaryFile]
+		if ret.Err != nil {
+			logger.Error(ret.Err, "skip input")
+			continue
+		}
+		ret.Info.DataFile = strings.TrimSuffix(binaryFiles[e.binaryFile].NoteFile, gcov.NoteFileExt)
+		results = append(results, ret.Info)
+	}
+	return results, nil
+}
@@ -0,0 +1,82 @@
+package gcovgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/yhlooo/gcovgo/pkg/cobertura"
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+	"github.com/yhlooo/gcovgo/pkg/lcov"
+	"github.com/yhlooo/gcovgo/pkg/sonarqube"
+)
+
+// writeResults 按指定格式输出解析结果
+func writeResults(ctx context.Context, w io.Writer, format string, results []*gcov.CoverageInfo) error {
+	// 所有结果输出为一个整体的格式
+	switch format {
+	case "lcov":
+		if _, err := io.WriteString(w, lcov.Text(ctx, results...)); err != nil {
+			return fmt.Errorf("write output error: %w", err)
+		}
+		return nil
+	case "cobertura":
+		root, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory error: %w", err)
+		}
+		raw, err := cobertura.XML(ctx, cobertura.Options{Root: root, Timestamp: time.Now()}, results...)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(raw); err != nil {
+			return fmt.Errorf("write output error: %w", err)
+		}
+		return nil
+	case "sonarqube":
+		root, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory error: %w", err)
+		}
+		raw, err := sonarqube.XML(ctx, sonarqube.Options{Root: root}, results...)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(raw); err != nil {
+			return fmt.Errorf("write output error: %w", err)
+		}
+		return nil
+	}
+
+	// 每个结果分别输出的格式
+	for _, ret := range results {
+		var outputContent []byte
+		switch format {
+		case "text":
+			outputContent = []byte(ret.IntermediateText(ctx))
+		case "json":
+			raw, err := ret.IntermediateJSON(ctx)
+			if err != nil {
+				return fmt.Errorf("marshal result to json error: %w", err)
+			}
+			buf := &bytes.Buffer{}
+			if err := json.Indent(buf, raw, "", "  "); err != nil {
+				return fmt.Errorf("indent json error: %w", err)
+			}
+			outputContent = buf.Bytes()
+		case "human-readable":
+			outputContent = []byte(ret.HumanReadableText(ctx))
+		default:
+			return fmt.Errorf("unknown output format: %q", format)
+		}
+		if _, err := fmt.Fprintln(w, string(outputContent)); err != nil {
+			return fmt.Errorf("write output error: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -1,12 +1,9 @@
 package gcovgo
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"runtime/pprof"
-	"strings"
 
 	"github.com/bombsimon/logrusr/v4"
 	"github.com/go-logr/logr"
@@ -22,13 +19,19 @@ func NewCommand(name string) *cobra.Command {
 	cpuProfile := ""
 	outputFormat := "human-readable"
 	outputFile := ""
+	primePaths := false
+	primePathsLines := false
+	inputOpts := &inputOptions{}
+	outputGCCVersion := ""
+	thresholdOpts := &thresholdOptions{}
+	baselineOpts := &baselineOptions{}
 
 	var cpuProfileOutput *os.File
 	cmd := &cobra.Command{
-		Use:          name + " {SOURCE|OBJ}...",
+		Use:          name + " {SOURCE|OBJ|BUILD_DIR|TRACEFILE.info|JSON}...",
 		Short:        "GCC code coverage tool",
 		SilenceUsage: true,
-		Args:         cobra.MinimumNArgs(1),
+		Args:         cobra.ArbitraryArgs,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			// 设置日志
 			setLogger(cmd, verbosity)
@@ -47,12 +50,39 @@ func NewCommand(name string) *cobra.Command {
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
-			logger := logr.FromContextOrDiscard(ctx)
+			if _, err := thresholdOpts.Thresholds(); err != nil {
+				return err
+			}
+
+			if outputGCCVersion != "" {
+				version, err := gcov.ParseVersion(outputGCCVersion)
+				if err != nil {
+					return err
+				}
+				// 不输出对应版本的 gcov 没有的中间格式
+				switch outputFormat {
+				case "text", "json":
+					if err := version.CheckIntermediateFormat(outputFormat == "json"); err != nil {
+						return fmt.Errorf("invalid --output-gcc-version for format %q: %w", outputFormat, err)
+					}
+				}
+				ctx = gcov.ContextWithOutputGCCVersion(ctx, version)
+			}
+			switch {
+			case primePathsLines:
+				ctx = gcov.ContextWithPrimePathsMode(ctx, gcov.PrimePathsList)
+			case primePaths:
+				ctx = gcov.ContextWithPrimePathsMode(ctx, gcov.PrimePathsSummary)
+			}
+
+			results, err := inputOpts.Resolve(ctx, args)
+			if err != nil {
+				return err
+			}
 
 			// 打开输出文件
 			w := os.Stdout
 			if outputFile != "" {
-				var err error
 				w, err = os.OpenFile(outputFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
 				if err != nil {
 					return fmt.Errorf("open output file %q error: %w", outputFile, err)
@@ -60,53 +90,13 @@ func NewCommand(name string) *cobra.Command {
 				defer func() { _ = w.Close() }()
 			}
 
-			resolvedNoteFiles := map[string]bool{}
-			for _, fileName := range args {
-				fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName))
-				noteFileName := fileName + ".gcno"
-				if resolvedNoteFiles[noteFileName] {
-					continue
-				}
-				resolvedNoteFiles[noteFileName] = true
-
-				dataFileName := fileName + ".gcda"
-				if _, err := os.Stat(dataFileName); err != nil {
-					if !os.IsNotExist(err) {
-						logger.Error(err, fmt.Sprintf("get data file %q info error", dataFileName))
-						continue
-					}
-					dataFileName = ""
-				}
-
-				ret, err := gcov.ResolveBinaryFile(noteFileName, dataFileName)
-				if err != nil {
-					logger.Error(err, fmt.Sprintf("resolve %q error", noteFileName))
-					continue
-				}
-				ret.DataFile = fileName
-				ret.GcovNoteFile = noteFileName
-				ret.GcovDataFile = dataFileName
-
-				var outputContent []byte
-				switch outputFormat {
-				case "text":
-					outputContent = []byte(ret.IntermediateText(ctx))
-				case "json":
-					outputContent, err = json.MarshalIndent(ret, "", "  ")
-					if err != nil {
-						return fmt.Errorf("marshal result to json error: %w", err)
-					}
-				case "human-readable":
-					outputContent = []byte(ret.HumanReadableText(ctx))
-				default:
-					return fmt.Errorf("unknown output format: %q", outputFormat)
-				}
-				if _, err = fmt.Fprintln(w, string(outputContent)); err != nil {
-					return fmt.Errorf("write output error: %w", err)
-				}
+			if err := writeResults(ctx, w, outputFormat, results); err != nil {
+				return err
 			}
-
-			return nil
+			if err := thresholdOpts.Check(results); err != nil {
+				return err
+			}
+			return baselineOpts.Check(ctx, results)
 		},
 		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
 			if cpuProfileOutput != nil {
@@ -128,12 +118,35 @@ func NewCommand(name string) *cobra.Command {
   human-readable : human readable format
   text           : intermediate text format
   json           : intermediate JSON format
+  lcov           : LCOV tracefile format, all inputs are written to a single tracefile
+  cobertura      : Cobertura XML format, all inputs are written to a single report,
+                   sources under the working directory use relative paths
+  sonarqube      : SonarQube generic test coverage XML format, all inputs are written to a single report,
+                   sources under the working directory use relative paths
 `)
 	fs.StringVarP(&outputFile, "output", "o", outputFile, "Write output to file instead of stdout")
+	fs.StringVar(
+		&outputGCCVersion, "output-gcc-version", outputGCCVersion,
+		"Write intermediate text or JSON as the specified gcc version (e.g. 9 or 9.5) would, "+
+			"instead of the version of the note file. Text is only for gcc before 9 and JSON only for gcc 9 and later",
+	)
+	inputOpts.AddFlags(fs)
+	inputOpts.AddRootFlags(fs)
+	fs.BoolVar(&primePaths, "prime-paths", primePaths, "Write prime path coverage summary of each function (prime path coverage in json format)")
+	fs.BoolVar(
+		&primePathsLines, "prime-paths-lines", primePathsLines,
+		"Write prime path coverage summary and coverage of each prime path of each function",
+	)
+	thresholdOpts.AddFlags(fs)
+	baselineOpts.AddFlags(fs)
 
 	// 添加子命令
 	cmd.AddCommand(
+		newCompareCommand(),
+		newDiffCoverCommand(),
 		newDumpCommand(),
+		newHTMLCommand(),
+		newSummaryCommand(),
 		newVersionCommand(),
 	)
 
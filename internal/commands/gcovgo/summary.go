@@ -0,0 +1,119 @@
+package gcovgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+)
+
+// newSummaryCommand 创建 summary 子命令
+func newSummaryCommand() *cobra.Command {
+	outputFormat := "table"
+	groupBy := "file"
+	inputOpts := &inputOptions{}
+	thresholdOpts := &thresholdOptions{}
+	baselineOpts := &baselineOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "summary {SOURCE|OBJ|BUILD_DIR|TRACEFILE.info|JSON}...",
+		Short: "Print line, function and branch coverage summary",
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if _, err := thresholdOpts.Thresholds(); err != nil {
+				return err
+			}
+
+			root, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get working directory error: %w", err)
+			}
+			results, err := inputOpts.Resolve(ctx, args)
+			if err != nil {
+				return err
+			}
+			report := gcov.Summarize(root, results...)
+
+			switch outputFormat {
+			case "table":
+				if err := writeSummaryTable(os.Stdout, report, groupBy); err != nil {
+					return err
+				}
+			case "json":
+				raw, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshal summary to json error: %w", err)
+				}
+				if _, err := fmt.Fprintln(os.Stdout, string(raw)); err != nil {
+					return fmt.Errorf("write output error: %w", err)
+				}
+			default:
+				return fmt.Errorf("unknown output format: %q", outputFormat)
+			}
+
+			if err := thresholdOpts.Check(results); err != nil {
+				return err
+			}
+			return baselineOpts.Check(ctx, results)
+		},
+	}
+
+	// 绑定选项到命令行参数
+	fs := cmd.Flags()
+	fs.StringVarP(&outputFormat, "format", "f", outputFormat, "Output format, one of (table, json)")
+	fs.StringVar(&groupBy, "by", groupBy, "Rows of the table, one of (file, directory)")
+	inputOpts.AddFlags(fs)
+	inputOpts.AddRootFlags(fs)
+	thresholdOpts.AddFlags(fs)
+	baselineOpts.AddFlags(fs)
+
+	return cmd
+}
+
+// writeSummaryTable 以表格形式输出覆盖率统计
+func writeSummaryTable(w io.Writer, report *gcov.SummaryReport, groupBy string) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	writeRow := func(name string, s gcov.Summary) {
+		_, _ = fmt.Fprintf(
+			tw, "%s\t%s\t%s\t%s\n", name,
+			summaryCell(s.LinesHit, s.LinesFound),
+			summaryCell(s.FunctionsHit, s.FunctionsFound),
+			summaryCell(s.BranchesHit, s.BranchesFound),
+		)
+	}
+
+	switch groupBy {
+	case "file":
+		_, _ = fmt.Fprintln(tw, "FILE\tLINES\tFUNCTIONS\tBRANCHES")
+		for _, f := range report.Files {
+			writeRow(f.Filename, f.Summary)
+		}
+	case "directory":
+		_, _ = fmt.Fprintln(tw, "DIRECTORY\tLINES\tFUNCTIONS\tBRANCHES")
+		for _, d := range report.Directories {
+			writeRow(d.Directory, d.Summary)
+		}
+	default:
+		return fmt.Errorf("unknown group by: %q", groupBy)
+	}
+	writeRow("TOTAL", report.Total)
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("write output error: %w", err)
+	}
+	return nil
+}
+
+// summaryCell 返回表格中的覆盖率单元格文本
+func summaryCell(hit, found int) string {
+	if found == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%% (%d/%d)", gcov.Percent(hit, found), hit, found)
+}
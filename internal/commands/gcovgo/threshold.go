@@ -0,0 +1,75 @@
+package gcovgo
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+)
+
+// thresholdOptions 覆盖率阈值选项
+type thresholdOptions struct {
+	Line     []string
+	Branch   []string
+	Function []string
+}
+
+// AddFlags 将选项绑定到命令行参数
+func (o *thresholdOptions) AddFlags(fs *pflag.FlagSet) {
+	const usage = "Exit with non-zero status if %s coverage is below PERCENT. " +
+		"Use GLOB=PERCENT to check each file matching GLOB instead of the total coverage. " +
+		"Can be specified multiple times"
+	fs.StringArrayVar(&o.Line, "fail-under-line", o.Line, fmt.Sprintf(usage, gcov.MetricLine))
+	fs.StringArrayVar(&o.Branch, "fail-under-branch", o.Branch, fmt.Sprintf(usage, gcov.MetricBranch))
+	fs.StringArrayVar(&o.Function, "fail-under-function", o.Function, fmt.Sprintf(usage, gcov.MetricFunction))
+}
+
+// Thresholds 返回解析后的覆盖率阈值
+func (o *thresholdOptions) Thresholds() ([]gcov.Threshold, error) {
+	var ret []gcov.Threshold
+	for _, item := range []struct {
+		metric gcov.Metric
+		values []string
+	}{
+		{metric: gcov.MetricLine, values: o.Line},
+		{metric: gcov.MetricBranch, values: o.Branch},
+		{metric: gcov.MetricFunction, values: o.Function},
+	} {
+		for _, v := range item.values {
+			th, err := gcov.ParseThreshold(item.metric, v)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, th)
+		}
+	}
+	return ret, nil
+}
+
+// Check 检查覆盖率是否达到阈值，未达到时返回列出所有未达到阈值情况的错误
+func (o *thresholdOptions) Check(results []*gcov.CoverageInfo) error {
+	thresholds, err := o.Thresholds()
+	if err != nil || len(thresholds) == 0 {
+		return err
+	}
+
+	root, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory error: %w", err)
+	}
+	violations := gcov.CheckThresholds(gcov.Summarize(root, results...), thresholds)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	msg := &strings.Builder{}
+	msg.WriteString("coverage is below thresholds:")
+	for _, v := range violations {
+		msg.WriteString("\n  ")
+		msg.WriteString(v.String())
+	}
+	return fmt.Errorf("%s", msg.String())
+}
@@ -0,0 +1,244 @@
+package cobertura
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+)
+
+// Header Cobertura XML 文件头
+const Header = xml.Header + "<!DOCTYPE coverage SYSTEM 'http://cobertura.sourceforge.net/xml/coverage-04.dtd'>\n"
+
+// Options 输出选项
+type Options struct {
+	// 源文件根目录，位于该目录下的源文件使用相对该目录的路径
+	Root string
+	// 报告生成时间
+	Timestamp time.Time
+}
+
+// XML 以 Cobertura XML 格式输出覆盖情况信息
+//
+// 源文件按所在目录划分为包，每个源文件对应一个类，函数对应类的方法
+func XML(ctx context.Context, opts Options, infos ...*gcov.CoverageInfo) ([]byte, error) {
+	raw, err := xml.MarshalIndent(NewCoverage(ctx, opts, infos...), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal cobertura xml error: %w", err)
+	}
+	return append([]byte(Header), append(raw, '\n')...), nil
+}
+
+// NewCoverage 创建 Cobertura 覆盖情况报告
+//
+// 同一源文件出现在多个覆盖情况信息中（比如被多个目标文件包含的头文件）时，先按 gcov.Merge 合并，每个源文件只对应一个类
+func NewCoverage(_ context.Context, opts Options, infos ...*gcov.CoverageInfo) *Coverage {
+	ret := &Coverage{
+		Complexity: "0",
+		Timestamp:  strconv.FormatInt(opts.Timestamp.Unix(), 10),
+		Version:    "gcovgo",
+	}
+	if opts.Root != "" {
+		ret.Sources = []string{opts.Root}
+	}
+
+	packages := map[string]*Package{}
+	total := counter{}
+	info := gcov.Merge(infos...)
+	for _, f := range info.Files {
+		filename := filepath.ToSlash(gcov.RelativePath(opts.Root, info.SourcePath(f.Filename)))
+		cls, c := newClass(filename, &f)
+
+		pkgName := strings.ReplaceAll(strings.Trim(path.Dir(filename), "/"), "/", ".")
+		if pkgName == "" {
+			pkgName = "."
+		}
+		pkg, ok := packages[pkgName]
+		if !ok {
+			pkg = &Package{Name: pkgName, Complexity: "0"}
+			packages[pkgName] = pkg
+		}
+		pkg.Classes = append(pkg.Classes, cls)
+		pkg.counter.add(c)
+		total.add(c)
+	}
+
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pkg := packages[name]
+		pkg.LineRate = rate(pkg.counter.linesCovered, pkg.counter.linesValid)
+		pkg.BranchRate = rate(pkg.counter.branchesCovered, pkg.counter.branchesValid)
+		ret.Packages = append(ret.Packages, *pkg)
+	}
+
+	ret.LineRate = rate(total.linesCovered, total.linesValid)
+	ret.BranchRate = rate(total.branchesCovered, total.branchesValid)
+	ret.LinesCovered = total.linesCovered
+	ret.LinesValid = total.linesValid
+	ret.BranchesCovered = total.branchesCovered
+	ret.BranchesValid = total.branchesValid
+	return ret
+}
+
+// Coverage Cobertura 覆盖情况报告
+type Coverage struct {
+	XMLName         xml.Name  `xml:"coverage"`
+	LineRate        string    `xml:"line-rate,attr"`
+	BranchRate      string    `xml:"branch-rate,attr"`
+	LinesCovered    int       `xml:"lines-covered,attr"`
+	LinesValid      int       `xml:"lines-valid,attr"`
+	BranchesCovered int       `xml:"branches-covered,attr"`
+	BranchesValid   int       `xml:"branches-valid,attr"`
+	Complexity      string    `xml:"complexity,attr"`
+	Timestamp       string    `xml:"timestamp,attr"`
+	Version         string    `xml:"version,attr"`
+	Sources         []string  `xml:"sources>source"`
+	Packages        []Package `xml:"packages>package"`
+}
+
+// Package Cobertura 包，对应一个目录
+type Package struct {
+	Name       string  `xml:"name,attr"`
+	LineRate   string  `xml:"line-rate,attr"`
+	BranchRate string  `xml:"branch-rate,attr"`
+	Complexity string  `xml:"complexity,attr"`
+	Classes    []Class `xml:"classes>class"`
+
+	counter counter
+}
+
+// Class Cobertura 类，对应一个源文件
+type Class struct {
+	Name       string   `xml:"name,attr"`
+	Filename   string   `xml:"filename,attr"`
+	LineRate   string   `xml:"line-rate,attr"`
+	BranchRate string   `xml:"branch-rate,attr"`
+	Complexity string   `xml:"complexity,attr"`
+	Methods    []Method `xml:"methods>method"`
+	Lines      []Line   `xml:"lines>line"`
+}
+
+// Method Cobertura 方法，对应一个函数
+type Method struct {
+	Name       string `xml:"name,attr"`
+	Signature  string `xml:"signature,attr"`
+	LineRate   string `xml:"line-rate,attr"`
+	BranchRate string `xml:"branch-rate,attr"`
+	Complexity string `xml:"complexity,attr"`
+	Lines      []Line `xml:"lines>line"`
+}
+
+// Line Cobertura 行
+type Line struct {
+	Number            uint32 `xml:"number,attr"`
+	Hits              uint64 `xml:"hits,attr"`
+	Branch            bool   `xml:"branch,attr"`
+	ConditionCoverage string `xml:"condition-coverage,attr,omitempty"`
+}
+
+// newClass 创建源文件对应的类，返回类及其统计数据
+func newClass(filename string, f *gcov.File) (Class, counter) {
+	cls := Class{
+		Name:       strings.ReplaceAll(strings.Trim(strings.TrimSuffix(filename, path.Ext(filename)), "/"), "/", "."),
+		Filename:   filename,
+		Complexity: "0",
+		Methods:    []Method{},
+		Lines:      make([]Line, 0, len(f.Lines)),
+	}
+
+	clsCounter := counter{}
+	for _, ln := range f.Lines {
+		l, c := newLine(&ln)
+		cls.Lines = append(cls.Lines, l)
+		clsCounter.add(c)
+	}
+	cls.LineRate = rate(clsCounter.linesCovered, clsCounter.linesValid)
+	cls.BranchRate = rate(clsCounter.branchesCovered, clsCounter.branchesValid)
+
+	for _, fn := range f.Functions {
+		m := Method{
+			Name:       fn.DemangledName,
+			Complexity: "0",
+			Lines:      []Line{},
+		}
+		if m.Name == "" {
+			m.Name = fn.Name
+		}
+		mCounter := counter{}
+		for _, ln := range f.Lines {
+			if ln.LineNumber < fn.StartLine || ln.LineNumber > fn.EndLine ||
+				(ln.FunctionName != "" && ln.FunctionName != fn.Name) {
+				continue
+			}
+			l, c := newLine(&ln)
+			m.Lines = append(m.Lines, l)
+			mCounter.add(c)
+		}
+		m.LineRate = rate(mCounter.linesCovered, mCounter.linesValid)
+		m.BranchRate = rate(mCounter.branchesCovered, mCounter.branchesValid)
+		cls.Methods = append(cls.Methods, m)
+	}
+
+	return cls, clsCounter
+}
+
+// newLine 创建 Cobertura 行，返回行及其统计数据
+func newLine(ln *gcov.Line) (Line, counter) {
+	c := counter{linesValid: 1}
+	if ln.Count > 0 {
+		c.linesCovered = 1
+	}
+	l := Line{Number: ln.LineNumber, Hits: ln.Count}
+	if len(ln.Branches) == 0 {
+		return l, c
+	}
+
+	c.branchesValid = len(ln.Branches)
+	for _, br := range ln.Branches {
+		if br.Count > 0 {
+			c.branchesCovered++
+		}
+	}
+	l.Branch = true
+	l.ConditionCoverage = fmt.Sprintf(
+		"%d%% (%d/%d)",
+		c.branchesCovered*100/c.branchesValid, c.branchesCovered, c.branchesValid,
+	)
+	return l, c
+}
+
+// counter 行和分支统计数据
+type counter struct {
+	linesValid      int
+	linesCovered    int
+	branchesValid   int
+	branchesCovered int
+}
+
+// add 累加统计数据
+func (c *counter) add(o counter) {
+	c.linesValid += o.linesValid
+	c.linesCovered += o.linesCovered
+	c.branchesValid += o.branchesValid
+	c.branchesCovered += o.branchesCovered
+}
+
+// rate 计算覆盖率，总数为 0 时覆盖率为 1
+func rate(covered, valid int) string {
+	if valid == 0 {
+		return "1"
+	}
+	return strconv.FormatFloat(math.Round(float64(covered)/float64(valid)*1e4)/1e4, 'f', -1, 64)
+}
@@ -0,0 +1,104 @@
+package cobertura
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+)
+
+// TestXML 测试 XML 方法
+func TestXML(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	info := &gcov.CoverageInfo{
+		CurrenWorkingDirectory: "/workdir",
+		Files: []gcov.File{
+			{
+				Filename:  "src/main.c",
+				Functions: []gcov.Function{{Name: "main", StartLine: 1, EndLine: 4, ExecutionCount: 1}},
+				Lines: []gcov.Line{
+					{LineNumber: 1, Count: 1, FunctionName: "main"},
+					{LineNumber: 2, Count: 1, FunctionName: "main", Branches: []gcov.Branch{{Count: 1}, {Count: 0}}},
+					{LineNumber: 3, Count: 0, FunctionName: "main"},
+				},
+			},
+			{
+				Filename: "/usr/include/stdio.h",
+				Lines:    []gcov.Line{{LineNumber: 10, Count: 2}},
+			},
+		},
+	}
+
+	raw, err := XML(t.Context(), Options{Root: "/workdir", Timestamp: time.Unix(1700000000, 0)}, info)
+	r.NoError(err)
+	a.Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE coverage SYSTEM 'http://cobertura.sourceforge.net/xml/coverage-04.dtd'>
+<coverage line-rate="0.75" branch-rate="0.5" lines-covered="3" lines-valid="4" branches-covered="1" branches-valid="2" complexity="0" timestamp="1700000000" version="gcovgo">
+  <sources>
+    <source>/workdir</source>
+  </sources>
+  <packages>
+    <package name="src" line-rate="0.6667" branch-rate="0.5" complexity="0">
+      <classes>
+        <class name="src.main" filename="src/main.c" line-rate="0.6667" branch-rate="0.5" complexity="0">
+          <methods>
+            <method name="main" signature="" line-rate="0.6667" branch-rate="0.5" complexity="0">
+              <lines>
+                <line number="1" hits="1" branch="false"></line>
+                <line number="2" hits="1" branch="true" condition-coverage="50% (1/2)"></line>
+                <line number="3" hits="0" branch="false"></line>
+              </lines>
+            </method>
+          </methods>
+          <lines>
+            <line number="1" hits="1" branch="false"></line>
+            <line number="2" hits="1" branch="true" condition-coverage="50% (1/2)"></line>
+            <line number="3" hits="0" branch="false"></line>
+          </lines>
+        </class>
+      </classes>
+    </package>
+    <package name="usr.include" line-rate="1" branch-rate="1" complexity="0">
+      <classes>
+        <class name="usr.include.stdio" filename="/usr/include/stdio.h" line-rate="1" branch-rate="1" complexity="0">
+          <methods></methods>
+          <lines>
+            <line number="10" hits="2" branch="false"></line>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>
+`, string(raw))
+}
+
+// TestNewCoverage_Merge 测试 NewCoverage 方法合并多个覆盖情况信息中的同一源文件
+func TestNewCoverage_Merge(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	header := func(cwd string, count uint64) *gcov.CoverageInfo {
+		return &gcov.CoverageInfo{
+			CurrenWorkingDirectory: cwd,
+			Files: []gcov.File{{
+				Filename: "../include/util.h",
+				Lines:    []gcov.Line{{LineNumber: 1, Count: count}, {LineNumber: 2}},
+			}},
+		}
+	}
+	c := NewCoverage(t.Context(), Options{Root: "/workdir"}, header("/workdir/a", 1), header("/workdir/b", 2))
+	r.Len(c.Packages, 1)
+	r.Len(c.Packages[0].Classes, 1)
+	cls := c.Packages[0].Classes[0]
+	a.Equal("include/util.h", cls.Filename)
+	r.Len(cls.Lines, 2)
+	a.Equal(uint64(3), cls.Lines[0].Hits)
+	a.Equal(2, c.LinesValid)
+	a.Equal(1, c.LinesCovered)
+}
@@ -0,0 +1,154 @@
+package diffcover
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+)
+
+// Report 变更代码覆盖率报告
+type Report struct {
+	// 变更的可执行行数
+	ChangedLines int `json:"changed_lines"`
+	// 变更的可执行行中有执行的行数
+	CoveredLines int `json:"covered_lines"`
+	// 各文件覆盖情况，仅包含有变更的可执行行的文件，按补丁中的顺序
+	Files []FileReport `json:"files"`
+}
+
+// FileReport 文件变更代码覆盖情况
+type FileReport struct {
+	// 文件路径，与补丁中的路径一致
+	Filename string `json:"file"`
+	// 变更的可执行行数
+	ChangedLines int `json:"changed_lines"`
+	// 变更的可执行行中有执行的行数
+	CoveredLines int `json:"covered_lines"`
+	// 变更的可执行行中未执行的行号
+	UncoveredLines []uint32 `json:"uncovered_lines"`
+}
+
+// Percent 返回变更代码行覆盖率百分比，没有变更的可执行行时返回 100
+func (r *Report) Percent() float64 {
+	return gcov.Percent(r.CoveredLines, r.ChangedLines)
+}
+
+// Percent 返回文件变更代码行覆盖率百分比，没有变更的可执行行时返回 100
+func (r *FileReport) Percent() float64 {
+	return gcov.Percent(r.CoveredLines, r.ChangedLines)
+}
+
+// Compute 计算补丁中变更代码的覆盖率
+//
+// 源文件路径位于 root 下时使用相对 root 的路径与补丁中的路径匹配。没有完全一致的源文件时，
+// 以补丁中的路径结尾的唯一源文件视为匹配；有多个这样的源文件时无法确定对应关系，记录日志并跳过该补丁文件。
+// 同一源文件出现在多个覆盖情况信息中（比如被多个目标文件包含的头文件）时，行执行次数累加
+func Compute(ctx context.Context, changes []ChangedFile, root string, infos ...*gcov.CoverageInfo) *Report {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	// 各源文件的行执行次数，未出现的行为不可执行行
+	counts := map[string]map[uint32]uint64{}
+	for _, info := range infos {
+		for _, f := range info.Files {
+			name := filepath.ToSlash(gcov.RelativePath(root, info.SourcePath(f.Filename)))
+			lines, ok := counts[name]
+			if !ok {
+				lines = map[uint32]uint64{}
+				counts[name] = lines
+			}
+			for _, ln := range f.Lines {
+				lines[ln.LineNumber] += ln.Count
+			}
+		}
+	}
+
+	ret := &Report{Files: []FileReport{}}
+	for _, change := range changes {
+		fr := FileReport{Filename: change.Filename, UncoveredLines: []uint32{}}
+		lines, ok := counts[change.Filename]
+		if !ok {
+			var matched []string
+			for name := range counts {
+				if strings.HasSuffix(name, "/"+change.Filename) {
+					matched = append(matched, name)
+				}
+			}
+			switch len(matched) {
+			case 0:
+				continue
+			case 1:
+				lines = counts[matched[0]]
+			default:
+				sort.Strings(matched)
+				logger.Info(fmt.Sprintf(
+					"WARN: skip ambiguous changed file %q, matched source files: %s",
+					change.Filename, strings.Join(matched, ", "),
+				))
+				continue
+			}
+		}
+		for _, lineNo := range change.Lines {
+			count, ok := lines[lineNo]
+			if !ok {
+				continue
+			}
+			fr.ChangedLines++
+			if count > 0 {
+				fr.CoveredLines++
+			} else {
+				fr.UncoveredLines = append(fr.UncoveredLines, lineNo)
+			}
+		}
+		if fr.ChangedLines == 0 {
+			continue
+		}
+		ret.ChangedLines += fr.ChangedLines
+		ret.CoveredLines += fr.CoveredLines
+		ret.Files = append(ret.Files, fr)
+	}
+	return ret
+}
+
+// Text 输出文本形式
+func (r *Report) Text() string {
+	ret := &strings.Builder{}
+	_, _ = fmt.Fprintf(
+		ret, "Diff coverage: %.1f%% (%d of %d changed executable lines covered)\n",
+		r.Percent(), r.CoveredLines, r.ChangedLines,
+	)
+	for _, f := range r.Files {
+		_, _ = fmt.Fprintf(ret, "%s: %.1f%% (%d/%d)", f.Filename, f.Percent(), f.CoveredLines, f.ChangedLines)
+		if len(f.UncoveredLines) > 0 {
+			_, _ = fmt.Fprintf(ret, ", uncovered lines: %s", gcov.LineRanges(f.UncoveredLines))
+		}
+		ret.WriteString("\n")
+	}
+	return ret.String()
+}
+
+// Markdown 输出 Markdown 形式，可用于合并请求评论
+func (r *Report) Markdown() string {
+	ret := &strings.Builder{}
+	ret.WriteString("## Diff Coverage\n\n")
+	_, _ = fmt.Fprintf(
+		ret, "**%.1f%%** (%d of %d changed executable lines covered)\n",
+		r.Percent(), r.CoveredLines, r.ChangedLines,
+	)
+	if len(r.Files) == 0 {
+		return ret.String()
+	}
+	ret.WriteString("\n| File | Coverage | Uncovered lines |\n| --- | --- | --- |\n")
+	for _, f := range r.Files {
+		_, _ = fmt.Fprintf(
+			ret, "| `%s` | %.1f%% (%d/%d) | %s |\n",
+			f.Filename, f.Percent(), f.CoveredLines, f.ChangedLines, gcov.LineRanges(f.UncoveredLines),
+		)
+	}
+	return ret.String()
+}
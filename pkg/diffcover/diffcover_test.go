@@ -0,0 +1,129 @@
+package diffcover
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+)
+
+const testPatch = `diff --git a/src/main.c b/src/main.c
+index 1111111..2222222 100644
+--- a/src/main.c
++++ b/src/main.c
+@@ -1,4 +1,7 @@
+ int main(int argc) {
+-  return 0;
++  if (argc > 1)
++    return 1;
++  // comment
++  return 0;
++}
+ 
+@@ -10 +13,2 @@ int f() {
++  f();
++  g();
+diff --git a/old.c b/old.c
+deleted file mode 100644
+--- a/old.c
++++ /dev/null
+@@ -1 +0,0 @@
+-int x;
+diff --git a/README.md b/README.md
+--- a/README.md
++++ b/README.md
+@@ -1 +1 @@
+-a
++b
+`
+
+// TestParsePatch 测试 ParsePatch 方法
+func TestParsePatch(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	changes, err := ParsePatch(strings.NewReader(testPatch), 1)
+	r.NoError(err)
+	a.Equal([]ChangedFile{
+		{Filename: "src/main.c", Lines: []uint32{2, 3, 4, 5, 6, 13, 14}},
+		{Filename: "README.md", Lines: []uint32{1}},
+	}, changes)
+}
+
+// TestCompute 测试 Compute 方法
+func TestCompute(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	changes, err := ParsePatch(strings.NewReader(testPatch), 1)
+	r.NoError(err)
+	info := &gcov.CoverageInfo{
+		CurrenWorkingDirectory: "/build",
+		Files: []gcov.File{{
+			Filename: "/workdir/src/main.c",
+			Lines: []gcov.Line{
+				{LineNumber: 1, Count: 1},
+				{LineNumber: 2, Count: 1},
+				{LineNumber: 3, Count: 0},
+				{LineNumber: 5, Count: 1},
+				{LineNumber: 13, Count: 0},
+				{LineNumber: 14, Count: 0},
+			},
+		}},
+	}
+
+	report := Compute(t.Context(), changes, "/workdir", info)
+	a.Equal(&Report{
+		ChangedLines: 5,
+		CoveredLines: 2,
+		Files: []FileReport{{
+			Filename:       "src/main.c",
+			ChangedLines:   5,
+			CoveredLines:   2,
+			UncoveredLines: []uint32{3, 13, 14},
+		}},
+	}, report)
+	a.Equal(`Diff coverage: 40.0% (2 of 5 changed executable lines covered)
+src/main.c: 40.0% (2/5), uncovered lines: 3, 13-14
+`, report.Text())
+	a.Equal("## Diff Coverage\n\n**40.0%** (2 of 5 changed executable lines covered)\n\n"+
+		"| File | Coverage | Uncovered lines |\n| --- | --- | --- |\n"+
+		"| `src/main.c` | 40.0% (2/5) | 3, 13-14 |\n", report.Markdown())
+}
+
+// TestCompute_Match 测试 Compute 方法匹配补丁文件与源文件
+func TestCompute_Match(t *testing.T) {
+	a := assert.New(t)
+
+	file := func(name string, count uint64) gcov.File {
+		return gcov.File{Filename: name, Lines: []gcov.Line{{LineNumber: 1, Count: count}}}
+	}
+	info := &gcov.CoverageInfo{Files: []gcov.File{
+		file("/workdir/src/main.c", 0),
+		file("/workdir/tests/src/main.c", 5),
+		file("/usr/include/util.h", 1),
+		file("/workdir/a/lib.c", 1),
+		file("/workdir/b/lib.c", 1),
+	}}
+	changes := []ChangedFile{
+		{Filename: "src/main.c", Lines: []uint32{1}},
+		{Filename: "include/util.h", Lines: []uint32{1}},
+		{Filename: "lib.c", Lines: []uint32{1}},
+	}
+
+	report := Compute(t.Context(), changes, "/workdir", info)
+	a.Equal(&Report{
+		ChangedLines: 2,
+		CoveredLines: 1,
+		Files: []FileReport{
+			// 存在完全一致的源文件时不使用以其结尾的其它源文件
+			{Filename: "src/main.c", ChangedLines: 1, UncoveredLines: []uint32{1}},
+			// 唯一以补丁路径结尾的源文件
+			{Filename: "include/util.h", ChangedLines: 1, CoveredLines: 1, UncoveredLines: []uint32{}},
+			// 多个源文件以补丁路径结尾时跳过
+		},
+	}, report)
+}
@@ -0,0 +1,131 @@
+package diffcover
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangedFile 补丁中有新增或修改行的文件
+type ChangedFile struct {
+	// 文件路径，已去除前缀
+	Filename string
+	// 新增或修改的行号，升序
+	Lines []uint32
+}
+
+var hunkHeaderRegexp = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// ParsePatch 解析 unified diff 格式的补丁，返回各文件新增或修改的行
+//
+// strip 为从文件路径开头去除的路径元素数目，与 patch -p 一致，比如 git diff 输出的补丁为 1 。
+// 被删除的文件不包含在结果中
+func ParsePatch(r io.Reader, strip int) ([]ChangedFile, error) {
+	var ret []ChangedFile
+	var cur *ChangedFile
+	// 当前块中剩余的新文件行数和当前行号
+	remaining := 0
+	lineNo := uint32(0)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := scanner.Text()
+
+		if remaining > 0 {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				if cur != nil {
+					cur.Lines = append(cur.Lines, lineNo)
+				}
+				lineNo++
+				remaining--
+				continue
+			case strings.HasPrefix(line, " "), line == "":
+				lineNo++
+				remaining--
+				continue
+			case strings.HasPrefix(line, "-"), strings.HasPrefix(line, `\`):
+				continue
+			}
+			// 块不完整，按新的头部处理
+			remaining = 0
+		}
+
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			name := strings.TrimPrefix(line, "+++ ")
+			if i := strings.IndexByte(name, '\t'); i >= 0 {
+				// 去除时间戳
+				name = name[:i]
+			}
+			name = unquote(strings.TrimSpace(name))
+			if name == "/dev/null" {
+				cur = nil
+				continue
+			}
+			ret = append(ret, ChangedFile{Filename: stripPath(name, strip)})
+			cur = &ret[len(ret)-1]
+		case strings.HasPrefix(line, "@@ "):
+			m := hunkHeaderRegexp.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("invalid hunk header at line %d: %q", n, line)
+			}
+			start, _ := strconv.ParseUint(m[1], 10, 32)
+			count := uint64(1)
+			if m[2] != "" {
+				count, _ = strconv.ParseUint(m[2], 10, 32)
+			}
+			lineNo = uint32(start)
+			remaining = int(count)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read patch error: %w", err)
+	}
+
+	// 合并同一文件的多段差异
+	merged := make([]ChangedFile, 0, len(ret))
+	index := map[string]int{}
+	for _, f := range ret {
+		i, ok := index[f.Filename]
+		if !ok {
+			merged = append(merged, ChangedFile{Filename: f.Filename})
+			i = len(merged) - 1
+			index[f.Filename] = i
+		}
+		merged[i].Lines = append(merged[i].Lines, f.Lines...)
+	}
+	for i := range merged {
+		sort.Slice(merged[i].Lines, func(a, b int) bool { return merged[i].Lines[a] < merged[i].Lines[b] })
+	}
+	return merged, nil
+}
+
+// stripPath 从路径开头去除 n 个路径元素
+func stripPath(name string, n int) string {
+	for ; n > 0; n-- {
+		i := strings.IndexByte(name, '/')
+		if i < 0 {
+			break
+		}
+		name = name[i+1:]
+	}
+	return name
+}
+
+// unquote 去除 git 对包含特殊字符的路径添加的引号
+func unquote(name string) string {
+	if len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"' {
+		if s, err := strconv.Unquote(name); err == nil {
+			return s
+		}
+	}
+	return name
+}
@@ -0,0 +1,161 @@
+package gcov
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ReadSummaryReportFile 读取 JSON 格式的覆盖率统计文件
+func ReadSummaryReportFile(fileName string) (*SummaryReport, error) {
+	raw, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("read summary file %q error: %w", fileName, err)
+	}
+	ret := &SummaryReport{}
+	if err := json.Unmarshal(raw, ret); err != nil {
+		return nil, fmt.Errorf("unmarshal summary file %q error: %w", fileName, err)
+	}
+	return ret, nil
+}
+
+// WriteSummaryReportFile 以 JSON 格式写入覆盖率统计文件
+func WriteSummaryReportFile(fileName string, report *SummaryReport) error {
+	raw, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal summary error: %w", err)
+	}
+	if err := os.WriteFile(fileName, append(raw, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write summary file %q error: %w", fileName, err)
+	}
+	return nil
+}
+
+// Regression 覆盖率相对基线下降的情况
+type Regression struct {
+	// 指标
+	Metric Metric
+	// 覆盖率下降的文件名，为空时表示整体覆盖率
+	Filename string
+	// 基线覆盖率百分比
+	Baseline float64
+	// 实际覆盖率百分比
+	Actual float64
+}
+
+// String 返回描述文本
+func (r Regression) String() string {
+	target := "total"
+	if r.Filename != "" {
+		target = r.Filename
+	}
+	return fmt.Sprintf("%s coverage of %s decreased from %.2f%% to %.2f%%", r.Metric, target, r.Baseline, r.Actual)
+}
+
+// baselineMetrics 与基线对比的指标
+var baselineMetrics = []Metric{MetricLine, MetricBranch}
+
+// CompareBaseline 对比当前覆盖率与基线，返回整体和各文件行、分支覆盖率下降超过 tolerance 个百分点的情况，
+// 以及是否有覆盖率提升
+//
+// 基线中不存在的文件（新增文件）和当前不存在的文件（删除的文件）不参与对比；基线中没有可执行行或分支的指标不参与对比，
+// 当前新增了可执行行或分支时视为提升
+func CompareBaseline(baseline, current *SummaryReport, tolerance float64) (regressions []Regression, improved bool) {
+	compare := func(filename string, base, cur Summary) {
+		for _, m := range baselineMetrics {
+			if base.found(m) == 0 {
+				if cur.found(m) > 0 {
+					improved = true
+				}
+				continue
+			}
+			b, c := base.Percent(m), cur.Percent(m)
+			switch {
+			case c < b-tolerance:
+				regressions = append(regressions, Regression{Metric: m, Filename: filename, Baseline: b, Actual: c})
+			case c > b:
+				improved = true
+			}
+		}
+	}
+
+	compare("", baseline.Total, current.Total)
+	baseFiles := summariesByFile(baseline.Files)
+	curFiles := summariesByFile(current.Files)
+	for _, f := range current.Files {
+		cur, ok := curFiles[f.Filename]
+		if !ok {
+			// 同名文件已对比
+			continue
+		}
+		delete(curFiles, f.Filename)
+		if base, ok := baseFiles[f.Filename]; ok {
+			compare(f.Filename, base, cur)
+		}
+	}
+	return regressions, improved
+}
+
+// UpdateBaseline 返回更新后的基线，整体和各文件的行、分支覆盖率均取基线和当前中较高的一方，保证基线不会下降
+//
+// 文件列表和其它指标以当前为准，基线中没有可执行行或分支的指标取当前的统计数据
+func UpdateBaseline(baseline, current *SummaryReport) *SummaryReport {
+	keepBest := func(base, cur Summary) Summary {
+		ret := cur
+		for _, m := range baselineMetrics {
+			if base.found(m) > 0 && base.Percent(m) > cur.Percent(m) {
+				ret.setMetric(m, base)
+			}
+		}
+		return ret
+	}
+
+	ret := &SummaryReport{
+		Total:       keepBest(baseline.Total, current.Total),
+		Directories: current.Directories,
+		Files:       make([]FileSummary, len(current.Files)),
+	}
+	baseFiles := summariesByFile(baseline.Files)
+	for i, f := range current.Files {
+		ret.Files[i] = f
+		if base, ok := baseFiles[f.Filename]; ok {
+			ret.Files[i].Summary = keepBest(base, f.Summary)
+		}
+	}
+	return ret
+}
+
+// found 返回统计数据中指定指标的总数
+func (s Summary) found(metric Metric) int {
+	switch metric {
+	case MetricBranch:
+		return s.BranchesFound
+	case MetricFunction:
+		return s.FunctionsFound
+	default:
+		return s.LinesFound
+	}
+}
+
+// setMetric 将 src 中指定指标的统计数据设置到 s
+func (s *Summary) setMetric(metric Metric, src Summary) {
+	switch metric {
+	case MetricBranch:
+		s.BranchesFound, s.BranchesHit = src.BranchesFound, src.BranchesHit
+	case MetricFunction:
+		s.FunctionsFound, s.FunctionsHit = src.FunctionsFound, src.FunctionsHit
+	default:
+		s.LinesFound, s.LinesHit = src.LinesFound, src.LinesHit
+	}
+}
+
+// summariesByFile 按文件名汇总覆盖率统计
+func summariesByFile(files []FileSummary) map[string]Summary {
+	ret := make(map[string]Summary, len(files))
+	for _, f := range files {
+		s := ret[f.Filename]
+		s.Add(f.Summary)
+		ret[f.Filename] = s
+	}
+	return ret
+}
@@ -0,0 +1,69 @@
+package gcov
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompareBaseline 测试 CompareBaseline 方法
+func TestCompareBaseline(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	baseline := &SummaryReport{
+		Total: Summary{LinesFound: 20, LinesHit: 10, BranchesFound: 4, BranchesHit: 2},
+		Files: []FileSummary{
+			{Filename: "a.c", Summary: Summary{LinesFound: 10, LinesHit: 5, BranchesFound: 4, BranchesHit: 2}},
+			{Filename: "b.c", Summary: Summary{LinesFound: 10, LinesHit: 5}},
+			{Filename: "deleted.c", Summary: Summary{LinesFound: 10, LinesHit: 10}},
+		},
+	}
+	fileName := filepath.Join(t.TempDir(), "baseline.json")
+	r.NoError(WriteSummaryReportFile(fileName, baseline))
+	baseline, err := ReadSummaryReportFile(fileName)
+	r.NoError(err)
+
+	current := &SummaryReport{
+		Total: Summary{LinesFound: 30, LinesHit: 16, BranchesFound: 4, BranchesHit: 2},
+		Files: []FileSummary{
+			{Filename: "a.c", Summary: Summary{LinesFound: 10, LinesHit: 6, BranchesFound: 4, BranchesHit: 2}},
+			{Filename: "b.c", Summary: Summary{LinesFound: 10, LinesHit: 4}},
+			{Filename: "new.c", Summary: Summary{LinesFound: 10, LinesHit: 6}},
+		},
+	}
+
+	regressions, improved := CompareBaseline(baseline, current, 0)
+	a.True(improved)
+	a.Equal([]Regression{{Metric: MetricLine, Filename: "b.c", Baseline: 50, Actual: 40}}, regressions)
+	a.Equal("line coverage of b.c decreased from 50.00% to 40.00%", regressions[0].String())
+
+	regressions, _ = CompareBaseline(baseline, current, 10)
+	a.Empty(regressions)
+
+	// 更新基线时在容忍范围内下降的文件保留基线中的覆盖率
+	updated := UpdateBaseline(baseline, current)
+	a.Equal(current.Total, updated.Total)
+	a.Equal([]FileSummary{
+		{Filename: "a.c", Summary: Summary{LinesFound: 10, LinesHit: 6, BranchesFound: 4, BranchesHit: 2}},
+		{Filename: "b.c", Summary: Summary{LinesFound: 10, LinesHit: 5}},
+		{Filename: "new.c", Summary: Summary{LinesFound: 10, LinesHit: 6}},
+	}, updated.Files)
+	regressions, _ = CompareBaseline(updated, current, 10)
+	a.Empty(regressions)
+	regressions, _ = CompareBaseline(updated, current, 5)
+	a.Len(regressions, 1)
+
+	// 基线中没有分支的文件新增部分覆盖的分支不视为下降
+	current.Files[1].BranchesFound, current.Files[1].BranchesHit = 2, 1
+	regressions, improved = CompareBaseline(baseline, current, 0)
+	a.True(improved)
+	a.NotContains(regressions, Regression{Metric: MetricBranch, Filename: "b.c", Baseline: 100, Actual: 50})
+	a.Len(regressions, 1)
+	a.Equal(
+		Summary{LinesFound: 10, LinesHit: 5, BranchesFound: 2, BranchesHit: 1},
+		UpdateBaseline(baseline, current).Files[1].Summary,
+	)
+}
@@ -0,0 +1,149 @@
+package cfg
+
+import (
+	"math"
+	"slices"
+)
+
+// LineCount 计算一行的执行次数， blocks 为以该行作为最后一行的块
+//
+// 与 gcov 一致，不直接累加块执行次数，而是累加从其它行进入这些块的边的执行次数，
+// 再加上完全处于该行内的环路的执行次数（比如 for (...) x++; 这样的单行循环）
+func LineCount(blocks []*Block) uint64 {
+	lc := &lineCounter{
+		blocks:  blocks,
+		csCount: map[*Arc]int64{},
+	}
+
+	count := int64(0)
+	for _, blk := range blocks {
+		for _, arc := range blk.in {
+			if !lc.hasBlock(arc.src) {
+				count += int64(arc.count)
+			}
+		}
+		for _, arc := range blk.out {
+			lc.csCount[arc] = int64(arc.count)
+		}
+	}
+
+	// 加上完全处于该行内的环路的执行次数
+	for _, blk := range blocks {
+		lc.blocked = nil
+		lc.blockLists = nil
+		lc.circuit(blk, blk, nil, &count)
+	}
+
+	if count < 0 {
+		return 0
+	}
+	return uint64(count)
+}
+
+// lineCounter 计算行执行次数时的状态
+//
+// 环路检测使用 K. A. Hawick 和 H. A. James 的 "Enumerating Circuits and Loops in Graphs with Self-Arcs and
+// Multiple-Arcs" 中的算法，与 gcov 一致
+type lineCounter struct {
+	// 行中的块
+	blocks []*Block
+	// 边剩余的执行次数，每找到一个环路减去环路的执行次数
+	csCount map[*Arc]int64
+
+	// 已阻塞的块
+	blocked []*Block
+	// 每个已阻塞的块阻塞的其它块，与 blocked 一一对应
+	blockLists [][]*Block
+}
+
+// hasBlock 判断块是否属于该行
+func (lc *lineCounter) hasBlock(blk *Block) bool {
+	return slices.Contains(lc.blocks, blk)
+}
+
+// circuit 查找从 v 回到 start 的环路，找到环路时将环路的执行次数累加到 count ，返回是否找到环路
+func (lc *lineCounter) circuit(v, start *Block, path []*Arc, count *int64) bool {
+	found := false
+
+	lc.blocked = append(lc.blocked, v)
+	lc.blockLists = append(lc.blockLists, nil)
+
+	for _, arc := range v.out {
+		w := arc.dst
+		if w.no < start.no || lc.csCount[arc] <= 0 || !lc.hasBlock(w) {
+			continue
+		}
+
+		path = append(path, arc)
+		if w == start {
+			// 找到环路
+			lc.handleCycle(path, count)
+			found = true
+		} else if !lc.hasNonPositiveArc(path) && !slices.Contains(lc.blocked, w) {
+			if lc.circuit(w, start, path, count) {
+				found = true
+			}
+		}
+		path = path[:len(path)-1]
+	}
+
+	if found {
+		lc.unblock(v)
+		return true
+	}
+
+	for _, arc := range v.out {
+		w := arc.dst
+		if w.no < start.no || lc.csCount[arc] <= 0 || !lc.hasBlock(w) {
+			continue
+		}
+		i := slices.Index(lc.blocked, w)
+		if i < 0 {
+			continue
+		}
+		if !slices.Contains(lc.blockLists[i], v) {
+			lc.blockLists[i] = append(lc.blockLists[i], v)
+		}
+	}
+
+	return false
+}
+
+// handleCycle 处理环路，找出环路中执行次数最少的边，累加到 count 并从环路各边中减去
+func (lc *lineCounter) handleCycle(path []*Arc, count *int64) {
+	cycleCount := int64(math.MaxInt64)
+	for _, arc := range path {
+		if c := lc.csCount[arc]; c < cycleCount {
+			cycleCount = c
+		}
+	}
+	*count += cycleCount
+	for _, arc := range path {
+		lc.csCount[arc] -= cycleCount
+	}
+}
+
+// hasNonPositiveArc 判断路径中是否包含剩余执行次数不为正数的边
+func (lc *lineCounter) hasNonPositiveArc(path []*Arc) bool {
+	for _, arc := range path {
+		if lc.csCount[arc] <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// unblock 解除块 u 以及被其阻塞的块的阻塞
+func (lc *lineCounter) unblock(u *Block) {
+	i := slices.Index(lc.blocked, u)
+	if i < 0 {
+		return
+	}
+	toUnblock := lc.blockLists[i]
+	lc.blocked = slices.Delete(lc.blocked, i, i+1)
+	lc.blockLists = slices.Delete(lc.blockLists, i, i+1)
+
+	for _, blk := range toUnblock {
+		lc.unblock(blk)
+	}
+}
@@ -0,0 +1,33 @@
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov/raw"
+)
+
+// TestLineCount 测试 LineCount 方法
+func TestLineCount(t *testing.T) {
+	a := assert.New(t)
+
+	// for (int i = 0; i < 10; i++) x++;
+	graph, err := BuildCFG(6, []*raw.RecordArcs{
+		{BlockNo: 0, Arcs: []raw.Arc{{DestBlock: 2, Flags: raw.ArcFlagOnTree}}},
+		{BlockNo: 2, Arcs: []raw.Arc{{DestBlock: 4, Flags: raw.ArcFlagOnTree}}},
+		{BlockNo: 3, Arcs: []raw.Arc{{DestBlock: 4}}},
+		{BlockNo: 4, Arcs: []raw.Arc{{DestBlock: 3, Flags: raw.ArcFlagOnTree}, {DestBlock: 5}}},
+		{BlockNo: 5, Arcs: []raw.Arc{{DestBlock: 1, Flags: raw.ArcFlagOnTree}}},
+	}, []uint64{10, 1})
+	a.NoError(err)
+	a.Equal(uint64(10), graph.Get(3).Count())
+	a.Equal(uint64(11), graph.Get(4).Count())
+
+	// 整个循环在同一行
+	a.Equal(uint64(11), LineCount([]*Block{graph.Get(2), graph.Get(3), graph.Get(4)}))
+	// 循环体单独一行
+	a.Equal(uint64(10), LineCount([]*Block{graph.Get(3)}))
+	// 只有入边
+	a.Equal(uint64(1), LineCount([]*Block{graph.Get(5)}))
+}
@@ -0,0 +1,77 @@
+package cfg
+
+import (
+	"slices"
+)
+
+// PrimePaths 计算控制流图中的质路径，返回按块编号字典序排序的质路径和是否计算完成
+//
+// 质路径指不是其它简单路径（或简单环路）子路径的简单路径（或简单环路）。
+// 与 gcc 一致，不考虑入口块（ 0 号块）和出口块（ 1 号块）。
+// 简单路径数目超过 limit 时放弃计算，返回 false ， limit 小于等于 0 表示不限制
+func (cfg CFG) PrimePaths(limit int) ([][]uint32, bool) {
+	// 邻接表
+	succ := make([][]uint32, len(cfg))
+	pred := make([][]uint32, len(cfg))
+	for i := range cfg {
+		if i < 2 {
+			continue
+		}
+		for _, arc := range cfg[i].out {
+			dst := arc.Destination().No()
+			if dst < 2 || slices.Contains(succ[i], dst) {
+				continue
+			}
+			succ[i] = append(succ[i], dst)
+			pred[dst] = append(pred[dst], uint32(i))
+		}
+	}
+
+	// extendable 判断简单路径能否向 next 中的块延伸成更长的简单路径或简单环路
+	extendable := func(path []uint32, end uint32, next []uint32) bool {
+		for _, blk := range next {
+			if blk == end || !slices.Contains(path, blk) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var ret [][]uint32
+	visited := 0
+	var walk func(path []uint32) bool
+	walk = func(path []uint32) bool {
+		visited++
+		if limit > 0 && visited > limit {
+			return false
+		}
+
+		first, last := path[0], path[len(path)-1]
+		if len(path) > 1 && first == last {
+			// 简单环路不能再延伸，总是质路径
+			ret = append(ret, slices.Clone(path))
+			return true
+		}
+		if !extendable(path, first, succ[last]) && !extendable(path, last, pred[first]) {
+			ret = append(ret, slices.Clone(path))
+		}
+
+		for _, blk := range succ[last] {
+			if blk != first && slices.Contains(path, blk) {
+				continue
+			}
+			if !walk(append(path, blk)) {
+				return false
+			}
+		}
+		return true
+	}
+	for i := 2; i < len(cfg); i++ {
+		if !walk([]uint32{uint32(i)}) {
+			return nil, false
+		}
+	}
+
+	slices.SortFunc(ret, slices.Compare[[]uint32])
+	return ret, true
+}
@@ -0,0 +1,43 @@
+package cfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov/raw"
+)
+
+// TestCFG_PrimePaths 测试 CFG.PrimePaths 方法
+func TestCFG_PrimePaths(t *testing.T) {
+	a := assert.New(t)
+
+	// 分支
+	graph, err := BuildCFG(6, []*raw.RecordArcs{
+		{BlockNo: 0, Arcs: []raw.Arc{{DestBlock: 2}}},
+		{BlockNo: 2, Arcs: []raw.Arc{{DestBlock: 3}, {DestBlock: 4}}},
+		{BlockNo: 3, Arcs: []raw.Arc{{DestBlock: 5}}},
+		{BlockNo: 4, Arcs: []raw.Arc{{DestBlock: 5}}},
+		{BlockNo: 5, Arcs: []raw.Arc{{DestBlock: 1}}},
+	}, nil)
+	a.NoError(err)
+	paths, ok := graph.PrimePaths(0)
+	a.True(ok)
+	a.Equal([][]uint32{{2, 3, 5}, {2, 4, 5}}, paths)
+
+	// 循环
+	graph, err = BuildCFG(5, []*raw.RecordArcs{
+		{BlockNo: 0, Arcs: []raw.Arc{{DestBlock: 2}}},
+		{BlockNo: 2, Arcs: []raw.Arc{{DestBlock: 3}}},
+		{BlockNo: 3, Arcs: []raw.Arc{{DestBlock: 2}, {DestBlock: 4}}},
+		{BlockNo: 4, Arcs: []raw.Arc{{DestBlock: 1}}},
+	}, nil)
+	a.NoError(err)
+	paths, ok = graph.PrimePaths(0)
+	a.True(ok)
+	a.Equal([][]uint32{{2, 3, 2}, {2, 3, 4}, {3, 2, 3}}, paths)
+
+	// 超出限制
+	_, ok = graph.PrimePaths(2)
+	a.False(ok)
+}
@@ -0,0 +1,219 @@
+package gcov
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// FileStatus 对比结果中文件的状态
+type FileStatus string
+
+// FileStatus 的可选值
+const (
+	FileAdded   FileStatus = "added"
+	FileRemoved FileStatus = "removed"
+	FileChanged FileStatus = "changed"
+)
+
+// Comparison 两次覆盖率结果的对比
+type Comparison struct {
+	// 对比前的整体统计
+	Before Summary `json:"before"`
+	// 对比后的整体统计
+	After Summary `json:"after"`
+	// 覆盖情况有变化的文件，按文件名排序
+	Files []FileComparison `json:"files"`
+}
+
+// FileComparison 文件覆盖情况的对比
+type FileComparison struct {
+	// 文件名
+	Filename string `json:"file"`
+	// 状态
+	Status FileStatus `json:"status"`
+	// 对比前的统计
+	Before Summary `json:"before"`
+	// 对比后的统计
+	After Summary `json:"after"`
+	// 新覆盖的行号
+	GainedLines []uint32 `json:"gained_lines"`
+	// 不再覆盖的行号
+	LostLines []uint32 `json:"lost_lines"`
+	// 新覆盖的函数
+	GainedFunctions []string `json:"gained_functions"`
+	// 不再覆盖的函数
+	LostFunctions []string `json:"lost_functions"`
+}
+
+// Compare 对比两次覆盖率结果
+//
+// 文件按源文件路径对应，位于 root 下的源文件使用相对 root 的路径。同一源文件出现在多个覆盖情况信息中时，
+// 先按 Merge 合并再统计，行有执行即视为覆盖，函数按名字对应。只在一侧存在的行或函数视为在另一侧未覆盖
+func Compare(root string, before, after []*CoverageInfo) *Comparison {
+	beforeFiles := coverageByFile(root, before)
+	afterFiles := coverageByFile(root, after)
+
+	ret := &Comparison{Files: []FileComparison{}}
+	names := make([]string, 0, len(beforeFiles)+len(afterFiles))
+	for name, f := range beforeFiles {
+		names = append(names, name)
+		ret.Before.Add(f.summary)
+	}
+	for name, f := range afterFiles {
+		if _, ok := beforeFiles[name]; !ok {
+			names = append(names, name)
+		}
+		ret.After.Add(f.summary)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b, inBefore := beforeFiles[name]
+		a, inAfter := afterFiles[name]
+		fc := FileComparison{
+			Filename:        name,
+			Status:          FileChanged,
+			GainedLines:     []uint32{},
+			LostLines:       []uint32{},
+			GainedFunctions: []string{},
+			LostFunctions:   []string{},
+		}
+		switch {
+		case !inBefore:
+			fc.Status = FileAdded
+			b = newFileCoverage()
+		case !inAfter:
+			fc.Status = FileRemoved
+			a = newFileCoverage()
+		}
+		fc.Before = b.summary
+		fc.After = a.summary
+
+		fc.GainedLines, fc.LostLines = diffSets(b.coveredLines, a.coveredLines)
+		fc.GainedFunctions, fc.LostFunctions = diffSets(b.coveredFunctions, a.coveredFunctions)
+		if fc.Status == FileChanged && fc.Before == fc.After &&
+			len(fc.GainedLines) == 0 && len(fc.LostLines) == 0 &&
+			len(fc.GainedFunctions) == 0 && len(fc.LostFunctions) == 0 {
+			continue
+		}
+		ret.Files = append(ret.Files, fc)
+	}
+
+	return ret
+}
+
+// Text 输出文本形式
+func (c *Comparison) Text() string {
+	ret := &strings.Builder{}
+	writeSummaryDelta(ret, "", c.Before, c.After)
+	for _, f := range c.Files {
+		_, _ = fmt.Fprintf(ret, "\n%s (%s)\n", f.Filename, f.Status)
+		writeSummaryDelta(ret, "  ", f.Before, f.After)
+		if len(f.GainedLines) > 0 {
+			_, _ = fmt.Fprintf(ret, "  gained lines: %s\n", LineRanges(f.GainedLines))
+		}
+		if len(f.LostLines) > 0 {
+			_, _ = fmt.Fprintf(ret, "  lost lines: %s\n", LineRanges(f.LostLines))
+		}
+		if len(f.GainedFunctions) > 0 {
+			_, _ = fmt.Fprintf(ret, "  gained functions: %s\n", strings.Join(f.GainedFunctions, ", "))
+		}
+		if len(f.LostFunctions) > 0 {
+			_, _ = fmt.Fprintf(ret, "  lost functions: %s\n", strings.Join(f.LostFunctions, ", "))
+		}
+	}
+	return ret.String()
+}
+
+// writeSummaryDelta 输出统计数据的变化
+func writeSummaryDelta(w *strings.Builder, indent string, before, after Summary) {
+	for _, item := range []struct {
+		name                       string
+		hitB, foundB, hitA, foundA int
+	}{
+		{"lines", before.LinesHit, before.LinesFound, after.LinesHit, after.LinesFound},
+		{"functions", before.FunctionsHit, before.FunctionsFound, after.FunctionsHit, after.FunctionsFound},
+		{"branches", before.BranchesHit, before.BranchesFound, after.BranchesHit, after.BranchesFound},
+	} {
+		b, a := Percent(item.hitB, item.foundB), Percent(item.hitA, item.foundA)
+		_, _ = fmt.Fprintf(
+			w, "%s%-10s %6.2f%% (%d/%d) -> %6.2f%% (%d/%d) %+.2f\n",
+			indent, item.name+":", b, item.hitB, item.foundB, a, item.hitA, item.foundA, a-b,
+		)
+	}
+}
+
+// fileCoverage 对比时单个源文件的覆盖情况
+type fileCoverage struct {
+	summary          Summary
+	coveredLines     map[uint32]bool
+	coveredFunctions map[string]bool
+}
+
+// newFileCoverage 创建 fileCoverage
+func newFileCoverage() *fileCoverage {
+	return &fileCoverage{
+		coveredLines:     map[uint32]bool{},
+		coveredFunctions: map[string]bool{},
+	}
+}
+
+// coverageByFile 按源文件汇总覆盖情况
+//
+// 先按 Merge 合并，同一源文件（比如被多个目标文件包含的头文件）只统计一次
+func coverageByFile(root string, infos []*CoverageInfo) map[string]*fileCoverage {
+	ret := map[string]*fileCoverage{}
+	info := Merge(infos...)
+	for i := range info.Files {
+		f := &info.Files[i]
+		fc := newFileCoverage()
+		fc.summary = f.Summary()
+		for _, ln := range f.Lines {
+			fc.coveredLines[ln.LineNumber] = ln.Count > 0
+		}
+		for _, fn := range f.Functions {
+			fc.coveredFunctions[fn.Name] = fc.coveredFunctions[fn.Name] || fn.ExecutionCount > 0
+		}
+		ret[filepath.ToSlash(RelativePath(root, info.SourcePath(f.Filename)))] = fc
+	}
+	return ret
+}
+
+// diffSets 返回在 after 中覆盖但在 before 中未覆盖的元素，以及在 before 中覆盖但在 after 中未覆盖的元素，均升序
+func diffSets[T uint32 | string](before, after map[T]bool) (gained, lost []T) {
+	gained, lost = []T{}, []T{}
+	for k, covered := range after {
+		if covered && !before[k] {
+			gained = append(gained, k)
+		}
+	}
+	for k, covered := range before {
+		if covered && !after[k] {
+			lost = append(lost, k)
+		}
+	}
+	slices.Sort(gained)
+	slices.Sort(lost)
+	return gained, lost
+}
+
+// LineRanges 将升序的行号压缩为范围文本，比如 3, 5-7
+func LineRanges(lines []uint32) string {
+	var parts []string
+	for i := 0; i < len(lines); {
+		j := i
+		for j+1 < len(lines) && lines[j+1] == lines[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, fmt.Sprintf("%d", lines[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", lines[i], lines[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,104 @@
+package gcov
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCompare 测试 Compare 方法
+func TestCompare(t *testing.T) {
+	a := assert.New(t)
+
+	before := []*CoverageInfo{{Files: []File{
+		{
+			Filename:  "a.c",
+			Functions: []Function{{Name: "f", ExecutionCount: 1}, {Name: "g"}},
+			Lines:     []Line{{LineNumber: 1, Count: 1}, {LineNumber: 2}, {LineNumber: 3, Count: 1}},
+		},
+		{Filename: "same.c", Lines: []Line{{LineNumber: 1, Count: 1}}},
+		{Filename: "old.c", Lines: []Line{{LineNumber: 1, Count: 1}}},
+	}}}
+	after := []*CoverageInfo{
+		{Files: []File{
+			{
+				Filename:  "a.c",
+				Functions: []Function{{Name: "f"}, {Name: "g", ExecutionCount: 1}},
+				Lines:     []Line{{LineNumber: 1, Count: 1}, {LineNumber: 2, Count: 1}, {LineNumber: 3}},
+			},
+			{Filename: "same.c", Lines: []Line{{LineNumber: 1, Count: 1}}},
+		}},
+		{Files: []File{{Filename: "same.c", Lines: []Line{{LineNumber: 1}}}}},
+	}
+
+	c := Compare("", before, after)
+	a.Equal(Summary{LinesFound: 5, LinesHit: 4, FunctionsFound: 2, FunctionsHit: 1}, c.Before)
+	// 多个覆盖情况信息中的 same.c 合并后只统计一次
+	a.Equal(Summary{LinesFound: 4, LinesHit: 3, FunctionsFound: 2, FunctionsHit: 1}, c.After)
+	a.Equal([]FileComparison{
+		{
+			Filename:        "a.c",
+			Status:          FileChanged,
+			Before:          Summary{LinesFound: 3, LinesHit: 2, FunctionsFound: 2, FunctionsHit: 1},
+			After:           Summary{LinesFound: 3, LinesHit: 2, FunctionsFound: 2, FunctionsHit: 1},
+			GainedLines:     []uint32{2},
+			LostLines:       []uint32{3},
+			GainedFunctions: []string{"g"},
+			LostFunctions:   []string{"f"},
+		},
+		{
+			Filename:        "old.c",
+			Status:          FileRemoved,
+			Before:          Summary{LinesFound: 1, LinesHit: 1},
+			GainedLines:     []uint32{},
+			LostLines:       []uint32{1},
+			GainedFunctions: []string{},
+			LostFunctions:   []string{},
+		},
+	}, c.Files)
+
+	text := c.Text()
+	a.Contains(text, "lines:      80.00% (4/5) ->  75.00% (3/4) -5.00\n")
+	a.Contains(text, `
+a.c (changed)
+  lines:      66.67% (2/3) ->  66.67% (2/3) +0.00
+  functions:  50.00% (1/2) ->  50.00% (1/2) +0.00
+  branches:  100.00% (0/0) -> 100.00% (0/0) +0.00
+  gained lines: 2
+  lost lines: 3
+  gained functions: g
+  lost functions: f
+`)
+}
+
+// TestCompare_SharedHeader 测试 Compare 方法对比被多个目标文件包含的头文件
+func TestCompare_SharedHeader(t *testing.T) {
+	a := assert.New(t)
+
+	header := func(cwd string, count uint64) *CoverageInfo {
+		return &CoverageInfo{
+			CurrenWorkingDirectory: cwd,
+			Files: []File{{
+				Filename:  "../include/util.h",
+				Functions: []Function{{Name: "util", StartLine: 1, ExecutionCount: count}},
+				Lines:     []Line{{LineNumber: 1, Count: count}, {LineNumber: 2}},
+			}},
+		}
+	}
+	before := []*CoverageInfo{header("/workdir/a", 0), header("/workdir/b", 0)}
+	after := []*CoverageInfo{header("/workdir/a", 0), header("/workdir/b", 3)}
+
+	c := Compare("/workdir", before, after)
+	a.Equal(Summary{LinesFound: 2, FunctionsFound: 1}, c.Before)
+	a.Equal(Summary{LinesFound: 2, LinesHit: 1, FunctionsFound: 1, FunctionsHit: 1}, c.After)
+	a.Equal([]FileComparison{{
+		Filename:        "include/util.h",
+		Status:          FileChanged,
+		Before:          Summary{LinesFound: 2, FunctionsFound: 1},
+		After:           Summary{LinesFound: 2, LinesHit: 1, FunctionsFound: 1, FunctionsHit: 1},
+		GainedLines:     []uint32{1},
+		LostLines:       []uint32{},
+		GainedFunctions: []string{"util"},
+		LostFunctions:   []string{},
+	}}, c.Files)
+}
@@ -18,3 +18,48 @@ func GCCVersionFromContext(ctx context.Context) Version {
 	}
 	return Version{}
 }
+
+// outputGCCVersionContextKey context.Context 中存储输出格式对应 gcc 版本的键
+type outputGCCVersionContextKey struct{}
+
+// ContextWithOutputGCCVersion 创建携带输出格式对应 gcc 版本的 context.Context
+//
+// 输出中间文本或 JSON 格式时，按该版本 gcov 的格式输出，而不是解析的 note 的版本
+func ContextWithOutputGCCVersion(ctx context.Context, version Version) context.Context {
+	return context.WithValue(ctx, outputGCCVersionContextKey{}, version)
+}
+
+// OutputGCCVersionFromContext 从 context.Context 获取输出格式对应 gcc 版本，未指定时返回 false
+func OutputGCCVersionFromContext(ctx context.Context) (Version, bool) {
+	v, ok := ctx.Value(outputGCCVersionContextKey{}).(Version)
+	return v, ok
+}
+
+// PrimePathsMode 质路径覆盖情况输出模式
+type PrimePathsMode int
+
+const (
+	// PrimePathsNone 不输出质路径覆盖情况
+	PrimePathsNone PrimePathsMode = iota
+	// PrimePathsSummary 仅输出每个函数质路径覆盖数目
+	PrimePathsSummary
+	// PrimePathsList 输出每个函数质路径覆盖数目和每条质路径覆盖情况
+	PrimePathsList
+)
+
+// primePathsModeContextKey context.Context 中存储质路径覆盖情况输出模式的键
+type primePathsModeContextKey struct{}
+
+// ContextWithPrimePathsMode 创建携带指定质路径覆盖情况输出模式的 context.Context
+func ContextWithPrimePathsMode(ctx context.Context, mode PrimePathsMode) context.Context {
+	return context.WithValue(ctx, primePathsModeContextKey{}, mode)
+}
+
+// PrimePathsModeFromContext 从 context.Context 获取质路径覆盖情况输出模式
+func PrimePathsModeFromContext(ctx context.Context) PrimePathsMode {
+	mode, ok := ctx.Value(primePathsModeContextKey{}).(PrimePathsMode)
+	if ok {
+		return mode
+	}
+	return PrimePathsNone
+}
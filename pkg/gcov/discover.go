@@ -0,0 +1,90 @@
+package gcov
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// NoteFileExt gcov note 文件扩展名
+const NoteFileExt = ".gcno"
+
+// DataFileExt gcov data 文件扩展名
+const DataFileExt = ".gcda"
+
+// DataFileName 返回 note 文件对应的 data 文件名
+//
+// 比如 CMakeFiles/tgt.dir/src/foo.c.gcno 对应 CMakeFiles/tgt.dir/src/foo.c.gcda
+func DataFileName(noteFileName string) string {
+	return strings.TrimSuffix(noteFileName, NoteFileExt) + DataFileExt
+}
+
+// DataFileOptions gcov data 文件查找选项，用于 data 文件与 note 文件不在同一目录树的情况
+type DataFileOptions struct {
+	// data 文件所在目录，按 note 文件的文件名查找 data 文件，与 gcov 的 -o 选项类似
+	ObjectDirectory string
+	// note 文件所在的构建目录，仅在 ObjectDirectory 不为空时生效，
+	// 不为空时按 note 文件相对该目录的路径在 ObjectDirectory 下查找 data 文件，避免不同目录中的同名 note 文件冲突
+	BuildDirectory string
+	// 运行时的 GCOV_PREFIX 环境变量， data 文件位于该目录下
+	Prefix string
+	// 运行时的 GCOV_PREFIX_STRIP 环境变量，即 data 文件路径去掉的前导目录层数，仅在 Prefix 不为空时生效
+	PrefixStrip int
+}
+
+// Validate 校验选项
+func (opts DataFileOptions) Validate() error {
+	if opts.ObjectDirectory != "" && opts.Prefix != "" {
+		return fmt.Errorf("object directory and gcov prefix can not be specified at the same time")
+	}
+	return nil
+}
+
+// LocateDataFile 返回 note 文件对应的 data 文件名
+//
+// 指定 ObjectDirectory 时为该目录下与 note 文件同名的 data 文件，note 文件位于 BuildDirectory 下时保留相对该目录的路径；
+// 指定 Prefix 时与 libgcov 一致，将 note 文件对应的 data 文件的绝对路径去掉 PrefixStrip 层前导目录后放在 Prefix 下；
+// 否则同 DataFileName
+func LocateDataFile(noteFileName string, opts DataFileOptions) string {
+	dataFileName := DataFileName(noteFileName)
+	switch {
+	case opts.ObjectDirectory != "":
+		name := filepath.Base(dataFileName)
+		if opts.BuildDirectory != "" {
+			if rel, err := filepath.Rel(opts.BuildDirectory, dataFileName); err == nil && filepath.IsLocal(rel) {
+				name = rel
+			}
+		}
+		return filepath.Join(opts.ObjectDirectory, name)
+	case opts.Prefix != "":
+		if abs, err := filepath.Abs(dataFileName); err == nil {
+			dataFileName = abs
+		}
+		parts := strings.Split(strings.TrimPrefix(filepath.ToSlash(dataFileName), "/"), "/")
+		strip := min(max(opts.PrefixStrip, 0), len(parts)-1)
+		return filepath.Join(append([]string{opts.Prefix}, parts[strip:]...)...)
+	default:
+		return dataFileName
+	}
+}
+
+// FindNoteFiles 递归查找目录下的所有 gcov note 文件，按路径排序返回
+func FindNoteFiles(dir string) ([]string, error) {
+	var ret []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), NoteFileExt) {
+			ret = append(ret, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk directory %q error: %w", dir, err)
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
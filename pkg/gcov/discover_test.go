@@ -0,0 +1,81 @@
+package gcov
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFindNoteFiles 测试 FindNoteFiles 方法
+func TestFindNoteFiles(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	dir := t.TempDir()
+	for _, name := range []string{
+		"CMakeFiles/tgt.dir/src/foo.c.gcno",
+		"CMakeFiles/tgt.dir/src/foo.c.gcda",
+		"CMakeFiles/tgt.dir/src/bar.c.gcno",
+		"main.gcno",
+		"main.o",
+	} {
+		r.NoError(os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		r.NoError(os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+
+	files, err := FindNoteFiles(dir)
+	r.NoError(err)
+	a.Equal([]string{
+		filepath.Join(dir, "CMakeFiles/tgt.dir/src/bar.c.gcno"),
+		filepath.Join(dir, "CMakeFiles/tgt.dir/src/foo.c.gcno"),
+		filepath.Join(dir, "main.gcno"),
+	}, files)
+	a.Equal(filepath.Join(dir, "CMakeFiles/tgt.dir/src/foo.c.gcda"), DataFileName(files[1]))
+}
+
+// TestLocateDataFile 测试 LocateDataFile 方法
+func TestLocateDataFile(t *testing.T) {
+	a := assert.New(t)
+
+	note := "/build/CMakeFiles/tgt.dir/src/foo.c.gcno"
+	a.Equal("/build/CMakeFiles/tgt.dir/src/foo.c.gcda", LocateDataFile(note, DataFileOptions{}))
+	a.Equal("/data/foo.c.gcda", LocateDataFile(note, DataFileOptions{ObjectDirectory: "/data"}))
+	// 保留相对构建目录的路径
+	a.Equal(
+		"/data/CMakeFiles/tgt.dir/src/foo.c.gcda",
+		LocateDataFile(note, DataFileOptions{ObjectDirectory: "/data", BuildDirectory: "/build"}),
+	)
+	a.Equal(
+		"/data/CMakeFiles/tgt.dir/src/foo.c.gcda",
+		LocateDataFile(
+			"build/CMakeFiles/tgt.dir/src/foo.c.gcno",
+			DataFileOptions{ObjectDirectory: "/data", BuildDirectory: "build"},
+		),
+	)
+	// 不在构建目录下时按文件名查找
+	a.Equal("/data/foo.c.gcda", LocateDataFile(note, DataFileOptions{ObjectDirectory: "/data", BuildDirectory: "/other"}))
+	a.Equal(
+		"/data/build/CMakeFiles/tgt.dir/src/foo.c.gcda",
+		LocateDataFile(note, DataFileOptions{Prefix: "/data"}),
+	)
+	a.Equal(
+		"/data/tgt.dir/src/foo.c.gcda",
+		LocateDataFile(note, DataFileOptions{Prefix: "/data", PrefixStrip: 2}),
+	)
+	a.Equal("/data/foo.c.gcda", LocateDataFile(note, DataFileOptions{Prefix: "/data", PrefixStrip: 100}))
+	// 未指定 GCOV_PREFIX 时 GCOV_PREFIX_STRIP 不生效
+	a.Equal("/build/CMakeFiles/tgt.dir/src/foo.c.gcda", LocateDataFile(note, DataFileOptions{PrefixStrip: 2}))
+}
+
+// TestDataFileOptions_Validate 测试 DataFileOptions.Validate 方法
+func TestDataFileOptions_Validate(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError(DataFileOptions{}.Validate())
+	a.NoError(DataFileOptions{ObjectDirectory: "/data", BuildDirectory: "/build"}.Validate())
+	a.NoError(DataFileOptions{Prefix: "/data", PrefixStrip: 2}.Validate())
+	a.Error(DataFileOptions{ObjectDirectory: "/data", Prefix: "/data"}.Validate())
+}
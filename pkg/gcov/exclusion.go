@@ -0,0 +1,255 @@
+package gcov
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+)
+
+// DefaultExclusionMarkerPrefixes 默认的排除标记前缀，分别对应 lcov 和 gcovr 的标记
+var DefaultExclusionMarkerPrefixes = []string{"LCOV_EXCL", "GCOVR_EXCL"}
+
+// Exclusions 源文件中通过排除标记排除的内容
+//
+// 支持的标记（以前缀 LCOV_EXCL 为例）：
+//
+//	LCOV_EXCL_LINE                   排除所在行
+//	LCOV_EXCL_START, LCOV_EXCL_STOP  排除两个标记之间（包含标记所在行）的行
+//	LCOV_EXCL_BR_LINE                排除所在行的分支
+//	LCOV_EXCL_BR_START, LCOV_EXCL_BR_STOP  排除两个标记之间（包含标记所在行）的分支
+type Exclusions struct {
+	// 排除的行
+	Lines map[uint32]bool
+	// 排除分支的行
+	BranchLines map[uint32]bool
+}
+
+// exclusionMarkerRegexp 返回匹配指定前缀的排除标记的正则表达式
+func exclusionMarkerRegexp(prefixes []string) *regexp.Regexp {
+	quoted := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		quoted[i] = regexp.QuoteMeta(prefix)
+	}
+	return regexp.MustCompile(`\b(?:` + strings.Join(quoted, "|") + `)_(BR_)?(LINE|START|STOP)\b`)
+}
+
+// ParseExclusionMarkers 解析源文件内容中以 prefixes 中任一前缀开头的排除标记
+//
+// 缺少 STOP 标记时排除到文件末尾
+func ParseExclusionMarkers(r io.Reader, prefixes []string) (*Exclusions, error) {
+	ret := &Exclusions{Lines: map[uint32]bool{}, BranchLines: map[uint32]bool{}}
+	if len(prefixes) == 0 {
+		return ret, nil
+	}
+	re := exclusionMarkerRegexp(prefixes)
+
+	inRegion, inBranchRegion := false, false
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for lineNo := uint32(1); scanner.Scan(); lineNo++ {
+		for _, m := range re.FindAllStringSubmatch(scanner.Text(), -1) {
+			branch := m[1] != ""
+			switch {
+			case m[2] == "LINE" && branch:
+				ret.BranchLines[lineNo] = true
+			case m[2] == "LINE":
+				ret.Lines[lineNo] = true
+			case m[2] == "START" && branch:
+				inBranchRegion = true
+			case m[2] == "START":
+				inRegion = true
+			case branch:
+				ret.BranchLines[lineNo] = true
+				inBranchRegion = false
+			default:
+				ret.Lines[lineNo] = true
+				inRegion = false
+			}
+		}
+		if inRegion {
+			ret.Lines[lineNo] = true
+		}
+		if inBranchRegion {
+			ret.BranchLines[lineNo] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read source error: %w", err)
+	}
+	return ret, nil
+}
+
+// ExcludeMarked 返回去掉源文件中排除标记所排除内容的覆盖情况信息，不修改原覆盖情况信息
+//
+// 被排除的行从 File.Lines 中去掉，起始行被排除的函数从 File.Functions 中去掉，被排除分支的行保留但去掉分支。
+// 源文件按 CoverageInfo.SourcePath 读取，读取失败时不排除任何内容
+func ExcludeMarked(ctx context.Context, prefixes []string, infos ...*CoverageInfo) []*CoverageInfo {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	cache := map[string]*Exclusions{}
+	getExclusions := func(fileName string) *Exclusions {
+		if exclusions, ok := cache[fileName]; ok {
+			return exclusions
+		}
+		exclusions, err := parseExclusionMarkersFile(fileName, prefixes)
+		if err != nil {
+			logger.V(1).Info(fmt.Sprintf("WARN: %v", err))
+		}
+		cache[fileName] = exclusions
+		return exclusions
+	}
+
+	ret := make([]*CoverageInfo, len(infos))
+	for i, info := range infos {
+		newInfo := *info
+		newInfo.Files = make([]File, len(info.Files))
+		for j := range info.Files {
+			f := &info.Files[j]
+			exclusions := getExclusions(info.SourcePath(f.Filename))
+			if exclusions == nil || (len(exclusions.Lines) == 0 && len(exclusions.BranchLines) == 0) {
+				newInfo.Files[j] = *f
+				continue
+			}
+			newInfo.Files[j] = f.exclude(exclusions)
+		}
+		ret[i] = &newInfo
+	}
+	return ret
+}
+
+// parseExclusionMarkersFile 解析源文件中的排除标记
+func parseExclusionMarkersFile(fileName string, prefixes []string) (*Exclusions, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("open source file %q error: %w", fileName, err)
+	}
+	defer func() { _ = f.Close() }()
+	ret, err := ParseExclusionMarkers(f, prefixes)
+	if err != nil {
+		return nil, fmt.Errorf("parse exclusion markers in %q error: %w", fileName, err)
+	}
+	return ret, nil
+}
+
+// exclude 返回去掉排除内容的文件覆盖情况
+func (f *File) exclude(exclusions *Exclusions) File {
+	ret := *f
+	ret.Functions = slices.DeleteFunc(slices.Clone(f.Functions), func(fn Function) bool {
+		return exclusions.Lines[fn.StartLine]
+	})
+	ret.Lines = excludeLines(f.Lines, exclusions)
+	ret.Instances = nil
+	for _, inst := range f.Instances {
+		if !slices.ContainsFunc(ret.Functions, func(fn Function) bool { return fn.Name == inst.FunctionName }) {
+			continue
+		}
+		ret.Instances = append(ret.Instances, FunctionInstance{
+			FunctionName: inst.FunctionName,
+			Lines:        excludeLines(inst.Lines, exclusions),
+		})
+	}
+	return ret
+}
+
+// excludeLines 返回去掉排除的行和分支的行覆盖情况
+func excludeLines(lines []Line, exclusions *Exclusions) []Line {
+	ret := make([]Line, 0, len(lines))
+	for _, ln := range lines {
+		if exclusions.Lines[ln.LineNumber] {
+			continue
+		}
+		if exclusions.BranchLines[ln.LineNumber] {
+			ln.Branches = []Branch{}
+		}
+		ret = append(ret, ln)
+	}
+	return ret
+}
+
+// BranchExclusionOptions 分支排除选项
+type BranchExclusionOptions struct {
+	// 是否排除异常分支（ Branch.Throw 为 true 的分支）
+	Throw bool
+	// 是否排除不可达的分支，即源码（去掉注释后）仅包含空白和花括号的行上的分支，这些分支由编译器生成，
+	// 比如析构函数调用、作用域结束时的清理代码
+	Unreachable bool
+}
+
+// ExcludeBranches 返回按选项去掉异常分支和不可达分支的覆盖情况信息，不修改原覆盖情况信息
+//
+// 排除不可达分支时源文件按 CoverageInfo.SourcePath 读取，读取失败时不排除该文件的不可达分支
+func ExcludeBranches(ctx context.Context, opts BranchExclusionOptions, infos ...*CoverageInfo) []*CoverageInfo {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	cache := map[string][]string{}
+	getSourceLines := func(fileName string) []string {
+		if lines, ok := cache[fileName]; ok {
+			return lines
+		}
+		var lines []string
+		content, err := os.ReadFile(fileName)
+		if err != nil {
+			logger.V(1).Info(fmt.Sprintf("WARN: read source file %q error: %v", fileName, err))
+		} else {
+			lines = strings.Split(string(content), "\n")
+		}
+		cache[fileName] = lines
+		return lines
+	}
+
+	ret := make([]*CoverageInfo, len(infos))
+	for i, info := range infos {
+		newInfo := *info
+		newInfo.Files = make([]File, len(info.Files))
+		for j := range info.Files {
+			f := info.Files[j]
+			var sourceLines []string
+			if opts.Unreachable {
+				sourceLines = getSourceLines(info.SourcePath(f.Filename))
+			}
+			f.Lines = excludeBranches(f.Lines, opts.Throw, sourceLines)
+			f.Instances = slices.Clone(f.Instances)
+			for k := range f.Instances {
+				f.Instances[k].Lines = excludeBranches(f.Instances[k].Lines, opts.Throw, sourceLines)
+			}
+			newInfo.Files[j] = f
+		}
+		ret[i] = &newInfo
+	}
+	return ret
+}
+
+// excludeBranches 返回去掉异常分支（ throw 为 true 时）和不可达分支（ sourceLines 不为空时）的行覆盖情况
+func excludeBranches(lines []Line, throw bool, sourceLines []string) []Line {
+	ret := make([]Line, len(lines))
+	for i, ln := range lines {
+		if len(ln.Branches) > 0 && ln.LineNumber > 0 && int(ln.LineNumber) <= len(sourceLines) &&
+			isNonCodeLine(sourceLines[ln.LineNumber-1]) {
+			ln.Branches = []Branch{}
+		}
+		if throw && slices.ContainsFunc(ln.Branches, func(br Branch) bool { return br.Throw }) {
+			ln.Branches = slices.DeleteFunc(slices.Clone(ln.Branches), func(br Branch) bool { return br.Throw })
+		}
+		ret[i] = ln
+	}
+	return ret
+}
+
+var (
+	// lineCommentRegexp 匹配行注释和单行内的块注释
+	lineCommentRegexp = regexp.MustCompile(`//.*$|/\*.*?\*/`)
+	// nonCodeRegexp 匹配仅包含空白和花括号的源码
+	nonCodeRegexp = regexp.MustCompile(`^[\s{}]*$`)
+)
+
+// isNonCodeLine 判断源码行去掉注释后是否仅包含空白和花括号
+func isNonCodeLine(line string) bool {
+	return nonCodeRegexp.MatchString(lineCommentRegexp.ReplaceAllString(line, ""))
+}
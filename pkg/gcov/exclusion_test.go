@@ -0,0 +1,150 @@
+package gcov
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exclusionTestSource = `int main(int argc, char **argv) {
+    if (argc > 1) { // LCOV_EXCL_BR_LINE
+        return 1;
+    }
+    abort(); // GCOVR_EXCL_LINE
+    // LCOV_EXCL_START
+    if (argc > 2) {
+        return 2;
+    }
+    // LCOV_EXCL_STOP
+    if (argc > 3) { // MY_EXCL_BR_LINE
+        return 3;
+    }
+    return 0;
+}
+
+void unused() { // MY_EXCL_START
+}
+`
+
+// TestParseExclusionMarkers 测试 ParseExclusionMarkers 方法
+func TestParseExclusionMarkers(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	exclusions, err := ParseExclusionMarkers(strings.NewReader(exclusionTestSource), DefaultExclusionMarkerPrefixes)
+	r.NoError(err)
+	a.Equal(map[uint32]bool{5: true, 6: true, 7: true, 8: true, 9: true, 10: true}, exclusions.Lines)
+	a.Equal(map[uint32]bool{2: true}, exclusions.BranchLines)
+
+	// 自定义前缀，缺少 STOP 标记时排除到文件末尾
+	exclusions, err = ParseExclusionMarkers(strings.NewReader(exclusionTestSource), []string{"MY_EXCL"})
+	r.NoError(err)
+	a.Equal(map[uint32]bool{17: true, 18: true}, exclusions.Lines)
+	a.Equal(map[uint32]bool{11: true}, exclusions.BranchLines)
+}
+
+// TestExcludeMarked 测试 ExcludeMarked 方法
+func TestExcludeMarked(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	dir := t.TempDir()
+	r.NoError(os.WriteFile(filepath.Join(dir, "main.c"), []byte(exclusionTestSource), 0o644))
+
+	twoBranches := func() []Branch { return []Branch{{Count: 1}, {Count: 0}} }
+	info := &CoverageInfo{
+		CurrenWorkingDirectory: dir,
+		Files: []File{
+			{
+				Filename: "main.c",
+				Functions: []Function{
+					{Name: "main", StartLine: 1, ExecutionCount: 1},
+					{Name: "unused", StartLine: 17},
+				},
+				Lines: []Line{
+					{LineNumber: 2, Count: 1, Branches: twoBranches()},
+					{LineNumber: 5, Count: 0},
+					{LineNumber: 7, Count: 1, Branches: twoBranches()},
+					{LineNumber: 11, Count: 1, Branches: twoBranches()},
+					{LineNumber: 17, Count: 0},
+				},
+			},
+			{Filename: "missing.c", Lines: []Line{{LineNumber: 1}}},
+		},
+	}
+
+	ret := ExcludeMarked(t.Context(), DefaultExclusionMarkerPrefixes, info)
+	r.Len(ret, 1)
+	a.Equal([]Function{
+		{Name: "main", StartLine: 1, ExecutionCount: 1},
+		{Name: "unused", StartLine: 17},
+	}, ret[0].Files[0].Functions)
+	a.Equal([]Line{
+		{LineNumber: 2, Count: 1, Branches: []Branch{}},
+		{LineNumber: 11, Count: 1, Branches: twoBranches()},
+		{LineNumber: 17, Count: 0},
+	}, ret[0].Files[0].Lines)
+	a.Equal(info.Files[1], ret[0].Files[1])
+
+	ret = ExcludeMarked(t.Context(), []string{"MY_EXCL"}, info)
+	a.Equal([]Function{{Name: "main", StartLine: 1, ExecutionCount: 1}}, ret[0].Files[0].Functions)
+	a.Len(ret[0].Files[0].Lines, 4)
+
+	// 不修改原覆盖情况信息
+	a.Len(info.Files[0].Functions, 2)
+	a.Len(info.Files[0].Lines, 5)
+	a.Len(info.Files[0].Lines[0].Branches, 2)
+}
+
+// TestExcludeBranches 测试 ExcludeBranches 方法
+func TestExcludeBranches(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	dir := t.TempDir()
+	r.NoError(os.WriteFile(filepath.Join(dir, "main.cpp"), []byte(`int main() {
+    std::string s = f();
+    if (s.empty()) {
+        return 1;
+    } // end if
+    return 0;
+}
+`), 0o644))
+
+	info := &CoverageInfo{
+		CurrenWorkingDirectory: dir,
+		Files: []File{{
+			Filename: "main.cpp",
+			Lines: []Line{
+				{LineNumber: 2, Count: 1, Branches: []Branch{{Count: 1, Fallthrough: true}, {Count: 0, Throw: true}}},
+				{LineNumber: 3, Count: 1, Branches: []Branch{{Count: 1}, {Count: 0}}},
+				{LineNumber: 5, Count: 1, Branches: []Branch{{Count: 1}, {Count: 0}}},
+				{LineNumber: 7, Count: 1, Branches: []Branch{{Count: 1}, {Count: 0, Throw: true}}},
+			},
+		}},
+	}
+
+	ret := ExcludeBranches(t.Context(), BranchExclusionOptions{Throw: true}, info)
+	a.Equal([]Line{
+		{LineNumber: 2, Count: 1, Branches: []Branch{{Count: 1, Fallthrough: true}}},
+		{LineNumber: 3, Count: 1, Branches: []Branch{{Count: 1}, {Count: 0}}},
+		{LineNumber: 5, Count: 1, Branches: []Branch{{Count: 1}, {Count: 0}}},
+		{LineNumber: 7, Count: 1, Branches: []Branch{{Count: 1}}},
+	}, ret[0].Files[0].Lines)
+
+	ret = ExcludeBranches(t.Context(), BranchExclusionOptions{Unreachable: true}, info)
+	a.Equal([]Line{
+		{LineNumber: 2, Count: 1, Branches: []Branch{{Count: 1, Fallthrough: true}, {Count: 0, Throw: true}}},
+		{LineNumber: 3, Count: 1, Branches: []Branch{{Count: 1}, {Count: 0}}},
+		{LineNumber: 5, Count: 1, Branches: []Branch{}},
+		{LineNumber: 7, Count: 1, Branches: []Branch{}},
+	}, ret[0].Files[0].Lines)
+
+	// 不修改原覆盖情况信息
+	a.Len(info.Files[0].Lines[0].Branches, 2)
+	a.Len(info.Files[0].Lines[2].Branches, 2)
+}
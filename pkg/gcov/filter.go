@@ -0,0 +1,226 @@
+package gcov
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// RegexPatternPrefix 正则表达式路径模式的前缀
+const RegexPatternPrefix = "regex:"
+
+// PathPattern 源文件路径模式，可以是 glob 模式或正则表达式
+type PathPattern struct {
+	raw string
+	re  *regexp.Regexp
+}
+
+// ParsePathPattern 解析源文件路径模式
+//
+// 以 regex: 开头时为正则表达式，匹配路径中的任意部分（需要匹配整个路径时使用 ^ 和 $ ），否则为 glob 模式，语法同 MatchGlob
+func ParsePathPattern(s string) (PathPattern, error) {
+	ret := PathPattern{raw: s}
+	if expr, ok := strings.CutPrefix(s, RegexPatternPrefix); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return ret, fmt.Errorf("invalid path pattern %q: %w", s, err)
+		}
+		ret.re = re
+		return ret, nil
+	}
+	if s == "" {
+		return ret, fmt.Errorf("invalid path pattern %q: empty pattern", s)
+	}
+	return ret, nil
+}
+
+// Match 判断路径是否匹配该模式
+func (p PathPattern) Match(name string) bool {
+	if p.re != nil {
+		return p.re.MatchString(name)
+	}
+	return MatchGlob(p.raw, name)
+}
+
+// String 返回模式原文
+func (p PathPattern) String() string {
+	return p.raw
+}
+
+// FileFilter 源文件过滤器
+type FileFilter struct {
+	// 源文件根目录，位于该目录下的源文件使用相对该目录的路径匹配，其它源文件使用绝对路径匹配
+	Root string
+	// 包含的源文件，为空时包含所有源文件
+	Include []PathPattern
+	// 排除的源文件，优先于 Include
+	Exclude []PathPattern
+}
+
+// Empty 判断过滤器是否不过滤任何源文件
+func (filter *FileFilter) Empty() bool {
+	return len(filter.Include) == 0 && len(filter.Exclude) == 0
+}
+
+// Match 判断规范化后的源文件路径是否通过过滤器
+func (filter *FileFilter) Match(name string) bool {
+	for _, p := range filter.Exclude {
+		if p.Match(name) {
+			return false
+		}
+	}
+	if len(filter.Include) == 0 {
+		return true
+	}
+	for _, p := range filter.Include {
+		if p.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter 返回仅包含通过过滤器的源文件的覆盖情况信息，不修改原覆盖情况信息
+//
+// 源文件路径先按 info.SourcePath 转为规范化的绝对路径，位于 filter.Root 下时再转为相对路径，统一使用 / 分隔
+func (info *CoverageInfo) Filter(filter *FileFilter) *CoverageInfo {
+	ret := *info
+	ret.Files = make([]File, 0, len(info.Files))
+	for _, f := range info.Files {
+		name := filepath.ToSlash(RelativePath(filter.Root, filepath.Clean(info.SourcePath(f.Filename))))
+		if filter.Match(name) {
+			ret.Files = append(ret.Files, f)
+		}
+	}
+	return &ret
+}
+
+// FunctionFilter 函数过滤器
+type FunctionFilter struct {
+	// 是否排除匹配的函数，否则包含匹配的函数
+	Exclude bool
+	// 匹配函数名或去混淆的函数名的正则表达式
+	Regexp *regexp.Regexp
+}
+
+// FunctionFilters 按顺序生效的函数过滤器，与 gcc 12 的 gcov --include 和 --exclude 语义一致
+//
+// 第一个过滤器为包含时，默认排除所有函数，否则默认包含所有函数；之后依次匹配各过滤器，最后一个匹配的过滤器决定是否保留
+type FunctionFilters []FunctionFilter
+
+// Keep 判断是否保留函数
+func (filters FunctionFilters) Keep(fn *Function) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	keep := filters[0].Exclude
+	for _, filter := range filters {
+		if filter.Regexp.MatchString(fn.Name) ||
+			(fn.DemangledName != "" && filter.Regexp.MatchString(fn.DemangledName)) {
+			keep = !filter.Exclude
+		}
+	}
+	return keep
+}
+
+// FilterFunctions 返回去掉未通过过滤器的函数及其所属行的覆盖情况信息，不修改原覆盖情况信息
+//
+// 函数内联到其它文件中的行也一并去掉。行同时属于多个函数时：
+//   - 存在函数实例的行覆盖情况（比如起始于同一行的 C++ 模板实例）时，仅保留该行在保留的函数实例中的覆盖情况；
+//   - 否则该行在保留的函数的范围内（且不在被去掉的函数内部，比如嵌套的 lambda ）时保留并归属到该函数，
+//     由于行覆盖情况已合并，其执行次数和分支仍包含被去掉的函数的部分
+func (info *CoverageInfo) FilterFunctions(filters FunctionFilters) *CoverageInfo {
+	ret := *info
+	if len(filters) == 0 {
+		return &ret
+	}
+	// 先汇总所有文件中去掉的函数，函数内联到其它文件中的行也归属于该函数
+	removed := map[string]bool{}
+	for i := range info.Files {
+		for j := range info.Files[i].Functions {
+			if fn := &info.Files[i].Functions[j]; !filters.Keep(fn) {
+				removed[fn.Name] = true
+			}
+		}
+	}
+	if len(removed) == 0 {
+		return &ret
+	}
+	ret.Files = make([]File, len(info.Files))
+	for i := range info.Files {
+		ret.Files[i] = info.Files[i].filterFunctions(removed)
+	}
+	return &ret
+}
+
+// filterFunctions 返回去掉指定函数及其所属行的文件覆盖情况
+func (f *File) filterFunctions(removed map[string]bool) File {
+	ret := File{
+		Filename:  f.Filename,
+		Functions: make([]Function, 0, len(f.Functions)),
+		Lines:     make([]Line, 0, len(f.Lines)),
+	}
+	// 本文件中去掉的函数
+	ownRemoved := map[string]*Function{}
+	for i := range f.Functions {
+		if removed[f.Functions[i].Name] {
+			ownRemoved[f.Functions[i].Name] = &f.Functions[i]
+		} else {
+			ret.Functions = append(ret.Functions, f.Functions[i])
+		}
+	}
+
+	// 保留的函数实例的行覆盖情况
+	var instanceLines []Line
+	hasInstances := map[string]bool{}
+	for _, inst := range f.Instances {
+		hasInstances[inst.FunctionName] = true
+		if removed[inst.FunctionName] {
+			continue
+		}
+		ret.Instances = append(ret.Instances, inst)
+		instanceLines = mergeLineSlices(instanceLines, inst.Lines)
+	}
+
+	j := 0
+	for _, ln := range f.Lines {
+		if !removed[ln.FunctionName] {
+			ret.Lines = append(ret.Lines, ln)
+			continue
+		}
+		for j < len(instanceLines) && instanceLines[j].LineNumber < ln.LineNumber {
+			j++
+		}
+		if j < len(instanceLines) && instanceLines[j].LineNumber == ln.LineNumber {
+			ret.Lines = append(ret.Lines, instanceLines[j])
+			continue
+		}
+		if fn := ownRemoved[ln.FunctionName]; fn != nil && ln.LineNumber > fn.StartLine && ln.LineNumber < fn.EndLine {
+			// 被去掉的函数内部的行
+			continue
+		}
+		if fn := coveringFunction(ret.Functions, hasInstances, ln.LineNumber); fn != nil {
+			ln.FunctionName = fn.Name
+			ret.Lines = append(ret.Lines, ln)
+		}
+	}
+	return ret
+}
+
+// coveringFunction 返回范围包含指定行且没有函数实例的行覆盖情况的最内层函数，不存在时返回 nil
+//
+// 没有结束行的函数仅包含起始行
+func coveringFunction(functions []Function, hasInstances map[string]bool, lineNo uint32) *Function {
+	var ret *Function
+	for i := range functions {
+		fn := &functions[i]
+		if hasInstances[fn.Name] || lineNo < fn.StartLine || lineNo > max(fn.EndLine, fn.StartLine) {
+			continue
+		}
+		if ret == nil || fn.StartLine > ret.StartLine {
+			ret = fn
+		}
+	}
+	return ret
+}
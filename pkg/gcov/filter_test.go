@@ -0,0 +1,166 @@
+package gcov
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCoverageInfo_Filter 测试 CoverageInfo.Filter 方法
+func TestCoverageInfo_Filter(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	info := &CoverageInfo{
+		CurrenWorkingDirectory: "/repo/build",
+		Files: []File{
+			{Filename: "../src/main.c"},
+			{Filename: "../src/./util.h"},
+			{Filename: "/usr/include/stdio.h"},
+			{Filename: "../third_party/zlib/zlib.h"},
+			{Filename: "../src/proto/foo.pb.cc"},
+		},
+	}
+	names := func(info *CoverageInfo) []string {
+		var ret []string
+		for _, f := range info.Files {
+			ret = append(ret, f.Filename)
+		}
+		return ret
+	}
+	mustParse := func(patterns ...string) []PathPattern {
+		var ret []PathPattern
+		for _, p := range patterns {
+			pattern, err := ParsePathPattern(p)
+			r.NoError(err)
+			ret = append(ret, pattern)
+		}
+		return ret
+	}
+
+	// 排除
+	a.Equal([]string{"../src/main.c", "../src/./util.h"}, names(info.Filter(&FileFilter{
+		Root:    "/repo",
+		Exclude: mustParse("/usr/include/**", "**/third_party/**", `regex:\.pb\.(h|cc)$`),
+	})))
+	// 包含
+	a.Equal([]string{"../src/./util.h"}, names(info.Filter(&FileFilter{
+		Root:    "/repo",
+		Include: mustParse("src/*.h"),
+	})))
+	// 排除优先
+	a.Equal([]string{"../src/main.c"}, names(info.Filter(&FileFilter{
+		Root:    "/repo",
+		Include: mustParse("regex:^src/"),
+		Exclude: mustParse("*.h", "**/proto/**"),
+	})))
+	// 不修改原覆盖情况信息
+	a.Len(info.Files, 5)
+
+	_, err := ParsePathPattern("regex:(")
+	a.Error(err)
+	_, err = ParsePathPattern("")
+	a.Error(err)
+}
+
+// TestCoverageInfo_FilterFunctions 测试 CoverageInfo.FilterFunctions 方法
+func TestCoverageInfo_FilterFunctions(t *testing.T) {
+	a := assert.New(t)
+
+	info := &CoverageInfo{Files: []File{{
+		Filename: "main.cpp",
+		Functions: []Function{
+			{Name: "_Z3addIiET_S0_S0_", DemangledName: "int add<int>(int, int)", StartLine: 1, ExecutionCount: 1},
+			{Name: "_Z3addIdET_S0_S0_", DemangledName: "double add<double>(double, double)", StartLine: 1},
+			{Name: "_Z10debug_dumpv", DemangledName: "debug_dump()", StartLine: 5},
+			{Name: "main", StartLine: 9, ExecutionCount: 1},
+		},
+		Lines: []Line{
+			{LineNumber: 2, Count: 1, FunctionName: "_Z3addIiET_S0_S0_"},
+			{LineNumber: 6, Count: 0, FunctionName: "_Z10debug_dumpv"},
+			{LineNumber: 10, Count: 1, FunctionName: "main"},
+		},
+		Instances: []FunctionInstance{
+			{FunctionName: "_Z3addIiET_S0_S0_", Lines: []Line{{LineNumber: 2, Count: 1, FunctionName: "_Z3addIiET_S0_S0_"}}},
+			{FunctionName: "_Z3addIdET_S0_S0_", Lines: []Line{{LineNumber: 2, Count: 0, FunctionName: "_Z3addIdET_S0_S0_"}}},
+		},
+	}}}
+	functionNames := func(info *CoverageInfo) []string {
+		var ret []string
+		for _, fn := range info.Files[0].Functions {
+			ret = append(ret, fn.Name)
+		}
+		return ret
+	}
+
+	// 按去混淆的函数名排除
+	ret := info.FilterFunctions(FunctionFilters{{Exclude: true, Regexp: regexp.MustCompile(`^debug_`)}})
+	a.Equal([]string{"_Z3addIiET_S0_S0_", "_Z3addIdET_S0_S0_", "main"}, functionNames(ret))
+	a.Equal([]Line{
+		{LineNumber: 2, Count: 1, FunctionName: "_Z3addIiET_S0_S0_"},
+		{LineNumber: 10, Count: 1, FunctionName: "main"},
+	}, ret.Files[0].Lines)
+
+	// 第一个过滤器为包含时默认排除，最后一个匹配的过滤器生效
+	ret = info.FilterFunctions(FunctionFilters{
+		{Regexp: regexp.MustCompile(`add`)},
+		{Exclude: true, Regexp: regexp.MustCompile(`<int>`)},
+	})
+	a.Equal([]string{"_Z3addIdET_S0_S0_"}, functionNames(ret))
+	a.Equal([]Line{{LineNumber: 2, Count: 0, FunctionName: "_Z3addIdET_S0_S0_"}}, ret.Files[0].Lines)
+	a.Len(ret.Files[0].Instances, 1)
+
+	// 不修改原覆盖情况信息
+	a.Len(info.Files[0].Functions, 4)
+	a.Len(info.Files[0].Lines, 3)
+}
+
+// TestCoverageInfo_FilterFunctions_SharedLines 测试 CoverageInfo.FilterFunctions 方法处理内联到其它文件和多个函数共享的行
+func TestCoverageInfo_FilterFunctions_SharedLines(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	info := &CoverageInfo{Files: []File{
+		{
+			Filename: "main.cpp",
+			Functions: []Function{
+				{Name: "main", StartLine: 3, EndLine: 10, ExecutionCount: 1},
+				{Name: "_ZZ4mainENKUlvE_clEv", DemangledName: "main::{lambda()#1}::operator()() const", StartLine: 4, EndLine: 6},
+				{Name: "_Z10debug_dumpv", DemangledName: "debug_dump()", StartLine: 12, EndLine: 14},
+			},
+			Lines: []Line{
+				{LineNumber: 3, Count: 1, FunctionName: "main"},
+				// lambda 与 main 共享的起始行
+				{LineNumber: 4, Count: 2, FunctionName: "_ZZ4mainENKUlvE_clEv"},
+				// lambda 内部的行
+				{LineNumber: 5, Count: 1, FunctionName: "_ZZ4mainENKUlvE_clEv"},
+				{LineNumber: 7, Count: 1, FunctionName: "main"},
+				{LineNumber: 13, Count: 0, FunctionName: "_Z10debug_dumpv"},
+			},
+		},
+		{
+			Filename: "util.h",
+			Lines: []Line{
+				// 内联到 debug_dump 和 main 中的行
+				{LineNumber: 2, Count: 0, FunctionName: "_Z10debug_dumpv"},
+				{LineNumber: 3, Count: 1, FunctionName: "main"},
+			},
+		},
+	}}
+
+	ret := info.FilterFunctions(FunctionFilters{{Exclude: true, Regexp: regexp.MustCompile(`lambda|debug_`)}})
+	r.Len(ret.Files, 2)
+	a.Equal([]Function{{Name: "main", StartLine: 3, EndLine: 10, ExecutionCount: 1}}, ret.Files[0].Functions)
+	a.Equal([]Line{
+		{LineNumber: 3, Count: 1, FunctionName: "main"},
+		{LineNumber: 4, Count: 2, FunctionName: "main"},
+		{LineNumber: 7, Count: 1, FunctionName: "main"},
+	}, ret.Files[0].Lines)
+	a.Equal([]Line{{LineNumber: 3, Count: 1, FunctionName: "main"}}, ret.Files[1].Lines)
+
+	// 不修改原覆盖情况信息
+	a.Equal("_ZZ4mainENKUlvE_clEv", info.Files[0].Lines[1].FunctionName)
+	a.Len(info.Files[1].Lines, 2)
+}
@@ -3,8 +3,10 @@ package gcov
 import (
 	"context"
 	"encoding"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -30,25 +32,68 @@ type CoverageInfo struct {
 }
 
 // IntermediateText 输出中间文本形式
+//
+// 默认按 GCCVersion 对应版本 gcov 的格式输出，可通过 ContextWithOutputGCCVersion 指定其它版本
 func (info *CoverageInfo) IntermediateText(ctx context.Context) string {
-	ctx = ContextWithGCCVersion(ctx, info.GCCVersion)
+	version := info.outputGCCVersion(ctx)
+	ctx = ContextWithGCCVersion(ctx, version)
 
-	ret := info.GCCVersion.IntermediateText(ctx)
+	ret := version.IntermediateText(ctx)
 	for _, file := range info.Files {
 		ret += file.IntermediateText(ctx)
 	}
 	return ret
 }
 
+// IntermediateJSON 输出 JSON 中间格式
+//
+// 默认按 GCCVersion 对应版本 gcov 的格式输出，可通过 ContextWithOutputGCCVersion 指定其它版本。
+// 与 gcov --prime-paths 一致，仅当通过 ContextWithPrimePathsMode 指定输出质路径覆盖情况时包含质路径覆盖情况
+func (info *CoverageInfo) IntermediateJSON(ctx context.Context) ([]byte, error) {
+	primePaths := PrimePathsModeFromContext(ctx) != PrimePathsNone
+	return json.Marshal(info.intermediateJSON(info.outputGCCVersion(ctx), primePaths))
+}
+
+// outputGCCVersion 返回输出格式对应的 gcc 版本
+func (info *CoverageInfo) outputGCCVersion(ctx context.Context) Version {
+	if version, ok := OutputGCCVersionFromContext(ctx); ok {
+		return version
+	}
+	return info.GCCVersion
+}
+
+// SourcePath 返回源文件路径
+//
+// 相对路径视为相对于执行编译的工作目录
+func (info *CoverageInfo) SourcePath(fileName string) string {
+	if filepath.IsAbs(fileName) || info.CurrenWorkingDirectory == "" {
+		return fileName
+	}
+	return filepath.Join(info.CurrenWorkingDirectory, fileName)
+}
+
+// RelativePath 返回 name 相对 root 的路径， root 为空或 name 不在 root 下时返回原路径
+func RelativePath(root, name string) string {
+	if root == "" {
+		return name
+	}
+	rel, err := filepath.Rel(root, name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return name
+	}
+	return rel
+}
+
 // HumanReadableText 输出人类可读的文本形式
 func (info *CoverageInfo) HumanReadableText(ctx context.Context) string {
 	logger := logr.FromContextOrDiscard(ctx)
 
 	ret := ""
 	for _, file := range info.Files {
-		fileContent, err := os.ReadFile(file.Filename)
+		sourcePath := info.SourcePath(file.Filename)
+		fileContent, err := os.ReadFile(sourcePath)
 		if err != nil {
-			logger.Info(fmt.Sprintf("WARN: read file %q error: %v", file.Filename, err))
+			logger.Info(fmt.Sprintf("WARN: read file %q error: %v", sourcePath, err))
 		}
 		ret += fmt.Sprintf(`        -:    0:Source:%s
         -:    0:Graph:%s
@@ -75,6 +120,38 @@ type Version struct {
 var _ fmt.Stringer = (*Version)(nil)
 var _ encoding.TextMarshaler = (*Version)(nil)
 
+// ParseVersion 解析形如 MAJOR[.MINOR[.PATCH]] 的 gcc 版本号
+//
+// 各部分均须为非负整数， PATCH 仅校验不保留
+func ParseVersion(s string) (Version, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid gcc version: %q", s)
+	}
+	var nums [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid gcc version: %q", s)
+		}
+		nums[i] = n
+	}
+	return Version{Major: nums[0], Minor: nums[1]}, nil
+}
+
+// CheckIntermediateFormat 检查该版本的 gcov 是否有指定的中间格式
+//
+// gcc 9 之前的 gcov 仅有中间文本格式， gcc 9 起仅有 JSON 中间格式
+func (v *Version) CheckIntermediateFormat(json bool) error {
+	switch {
+	case json && v.Major < 9:
+		return fmt.Errorf("gcov of gcc %s has no intermediate json format, which is supported since gcc 9", v)
+	case !json && v.Major >= 9:
+		return fmt.Errorf("gcov of gcc %s has no intermediate text format, which is replaced by json since gcc 9", v)
+	}
+	return nil
+}
+
 // IntermediateText 输出中间文本形式
 func (v *Version) IntermediateText(ctx context.Context) string {
 	if version := GCCVersionFromContext(ctx); version.Major < 8 {
@@ -93,6 +170,22 @@ func (v *Version) MarshalText() ([]byte, error) {
 	return []byte(v.String()), nil
 }
 
+// UnmarshalText 从文本反序列化
+//
+// 忽略版本号后的其它内容，比如 gcov 输出的 "13.2.1 20231011"
+func (v *Version) UnmarshalText(text []byte) error {
+	s := string(text)
+	if i := strings.IndexAny(s, " -+~"); i >= 0 {
+		s = s[:i]
+	}
+	version, err := ParseVersion(s)
+	if err != nil {
+		return err
+	}
+	*v = version
+	return nil
+}
+
 // File 文件覆盖情况信息
 type File struct {
 	// 文件名
@@ -101,6 +194,10 @@ type File struct {
 	Functions []Function `json:"functions,omitempty"`
 	// 文件中行覆盖情况
 	Lines []Line `json:"lines,omitempty"`
+	// 起始于同一行的多个函数（比如 C++ 模板实例）各自的行覆盖情况
+	//
+	// Lines 中为这些函数的汇总
+	Instances []FunctionInstance `json:"-"`
 }
 
 // IntermediateText 输出中间文本形式
@@ -125,56 +222,134 @@ func (f *File) HumanReadableText(ctx context.Context, content []byte) string {
 
 	fnI := 0
 	lnI := 0
+	// 起始于同一行的函数及其结束行号
+	var group []Function
+	groupEnd := uint32(0)
 
 	ret := ""
 	for i := 0; i < linesN; i++ {
-		// 获取行内容
-		lnContent := "/*EOF*/"
-		if i < len(lines) {
-			lnContent = lines[i]
-		}
+		lineNo := uint32(i + 1)
 
 		// 获取行执行次数和分支信息
-		count := "-"
-		var brs []Branch
-		var calls []Branch
-		if lnI < len(f.Lines) {
-			ln := f.Lines[lnI]
-			if ln.LineNumber == uint32(i+1) {
-				count = strconv.FormatUint(ln.Count, 10)
-				brs = ln.Branches
-				calls = ln.CallBranches
-				lnI++
+		var ln *Line
+		if lnI < len(f.Lines) && f.Lines[lnI].LineNumber == lineNo {
+			ln = &f.Lines[lnI]
+			lnI++
+		}
+
+		// 获取起始于该行的函数
+		var fns []Function
+		for fnI < len(f.Functions) && f.Functions[fnI].StartLine <= lineNo {
+			if f.Functions[fnI].StartLine == lineNo {
+				fns = append(fns, f.Functions[fnI])
 			}
+			fnI++
 		}
+		switch {
+		case len(fns) == 1:
+			ret += fns[0].HumanReadableText(ctx)
+		case len(fns) > 1:
+			group = fns
+			for _, fn := range fns {
+				if fn.EndLine > groupEnd {
+					groupEnd = fn.EndLine
+				}
+			}
+			if groupEnd < lineNo {
+				groupEnd = lineNo
+			}
+		}
+
+		ret += humanReadableLineText(ctx, lineNo, lines, ln)
 
-		// 获取分支信息
-		if fnI < len(f.Functions) {
-			fn := f.Functions[fnI]
-			if fn.StartLine == uint32(i+1) {
+		// 起始于同一行的函数分别输出
+		if len(group) > 0 && groupEnd == lineNo {
+			for _, fn := range group {
+				ret += humanReadableFunctionSeparator
+				ret += fn.Name + ":\n"
 				ret += fn.HumanReadableText(ctx)
-				fnI++
+				ret += f.instanceHumanReadableText(ctx, fn, lines)
 			}
+			ret += humanReadableFunctionSeparator
+			group = nil
+			groupEnd = 0
 		}
+	}
+
+	return ret
+}
 
-		ret += fmt.Sprintf(" %8s: %4d:%s\n", count, i+1, lnContent)
-		for j, br := range brs {
-			ret += br.HumanReadableText(ctx, j, false)
+// humanReadableFunctionSeparator 人类可读的文本形式中起始于同一行的函数之间的分隔行
+const humanReadableFunctionSeparator = "------------------\n"
+
+// instanceHumanReadableText 以人类可读的文本形式输出起始于同一行的函数中的一个
+func (f *File) instanceHumanReadableText(ctx context.Context, fn Function, lines []string) string {
+	var instanceLines []Line
+	for _, instance := range f.Instances {
+		if instance.FunctionName == fn.Name {
+			instanceLines = instance.Lines
+			break
 		}
-		for j, br := range calls {
-			ret += br.HumanReadableText(ctx, j, true)
+	}
+
+	end := fn.EndLine
+	if len(instanceLines) > 0 && instanceLines[len(instanceLines)-1].LineNumber > end {
+		end = instanceLines[len(instanceLines)-1].LineNumber
+	}
+
+	ret := ""
+	lnI := 0
+	for lineNo := fn.StartLine; lineNo <= end; lineNo++ {
+		for lnI < len(instanceLines) && instanceLines[lnI].LineNumber < lineNo {
+			lnI++
 		}
+		var ln *Line
+		if lnI < len(instanceLines) && instanceLines[lnI].LineNumber == lineNo {
+			ln = &instanceLines[lnI]
+		}
+		ret += humanReadableLineText(ctx, lineNo, lines, ln)
+	}
+	return ret
+}
+
+// humanReadableLineText 以人类可读的文本形式输出一行及其分支信息
+func humanReadableLineText(ctx context.Context, lineNo uint32, lines []string, ln *Line) string {
+	// 获取行内容
+	lnContent := "/*EOF*/"
+	if int(lineNo) <= len(lines) {
+		lnContent = lines[lineNo-1]
+	}
+
+	if ln == nil {
+		return fmt.Sprintf(" %8s: %4d:%s\n", "-", lineNo, lnContent)
 	}
 
+	ret := fmt.Sprintf(" %8s: %4d:%s\n", strconv.FormatUint(ln.Count, 10), lineNo, lnContent)
+	for j, br := range ln.Branches {
+		ret += br.HumanReadableText(ctx, j, false)
+	}
+	for j, br := range ln.CallBranches {
+		ret += br.HumanReadableText(ctx, j, true)
+	}
 	return ret
 }
 
+// FunctionInstance 与其它函数起始于同一行的函数（比如 C++ 模板实例、头文件中的 static inline 函数）的行覆盖情况
+type FunctionInstance struct {
+	// 函数名
+	FunctionName string
+	// 函数在所在文件中的行覆盖情况
+	Lines []Line
+}
+
 // Function 函数覆盖情况信息
 type Function struct {
 	// 函数名
 	Name string `json:"name"`
 	// 去混淆的函数名
 	DemangledName string `json:"demangled_name,omitempty"`
+	// 是否编译器生成的函数（比如 C++ 静态初始化函数）
+	Artificial bool `json:"artificial,omitempty"`
 
 	// 起始行号
 	StartLine uint32 `json:"start_line"`
@@ -193,6 +368,13 @@ type Function struct {
 	ExecutionCount uint64 `json:"execution_count"`
 	// 函数返回次数
 	ReturnCount uint64 `json:"-"`
+
+	// 质路径总数
+	TotalPrimePaths uint32 `json:"total_prime_paths,omitempty"`
+	// 已覆盖的质路径数目
+	CoveredPrimePaths uint32 `json:"covered_prime_paths,omitempty"`
+	// 质路径覆盖情况
+	PrimePaths []PrimePath `json:"prime_path_coverage,omitempty"`
 }
 
 // IntermediateText 输出中间文本形式
@@ -204,7 +386,7 @@ func (fn *Function) IntermediateText(ctx context.Context) string {
 }
 
 // HumanReadableText 输出人类可读的文本形式
-func (fn *Function) HumanReadableText(_ context.Context) string {
+func (fn *Function) HumanReadableText(ctx context.Context) string {
 	returned := uint64(0)
 	if fn.ExecutionCount != 0 {
 		returned = fn.ReturnCount * 100 / fn.ExecutionCount
@@ -213,10 +395,51 @@ func (fn *Function) HumanReadableText(_ context.Context) string {
 	if fn.Blocks != 0 {
 		executed = fn.BlocksExecuted * 100 / fn.Blocks
 	}
-	return fmt.Sprintf(
+	ret := fmt.Sprintf(
 		"function %s called %d returned %d%% blocks executed %d%%\n",
 		fn.Name, fn.ExecutionCount, returned, executed,
 	)
+
+	// 质路径覆盖情况
+	mode := PrimePathsModeFromContext(ctx)
+	if mode == PrimePathsNone || fn.TotalPrimePaths == 0 {
+		return ret
+	}
+	ret += fmt.Sprintf("paths covered %d of %d\n", fn.CoveredPrimePaths, fn.TotalPrimePaths)
+	if mode == PrimePathsList {
+		for _, p := range fn.PrimePaths {
+			ret += p.HumanReadableText(ctx)
+		}
+	}
+	return ret
+}
+
+// PrimePath 质路径覆盖情况信息
+type PrimePath struct {
+	// 质路径编号
+	ID uint32 `json:"id"`
+	// 质路径依次经过的块编号
+	//
+	// 仅当能从控制流图重建出与 note 中数目一致的质路径时有值
+	Blocks []uint32 `json:"sequence,omitempty"`
+	// 是否已覆盖
+	Covered bool `json:"covered"`
+}
+
+// HumanReadableText 输出人类可读的文本形式
+func (p *PrimePath) HumanReadableText(_ context.Context) string {
+	state := "not covered"
+	if p.Covered {
+		state = "covered"
+	}
+	if len(p.Blocks) == 0 {
+		return fmt.Sprintf("path %3d %s\n", p.ID, state)
+	}
+	blocks := make([]string, len(p.Blocks))
+	for i, blk := range p.Blocks {
+		blocks[i] = strconv.FormatUint(uint64(blk), 10)
+	}
+	return fmt.Sprintf("path %3d %s: %s\n", p.ID, state, strings.Join(blocks, " "))
 }
 
 // Line 覆盖情况信息
@@ -225,6 +448,8 @@ type Line struct {
 	LineNumber uint32 `json:"line_number"`
 	// 执行次数
 	Count uint64 `json:"count"`
+	// 以该行作为最后一行的块的编号
+	BlockIDs []uint32 `json:"block_ids,omitempty"`
 	// 分支
 	Branches []Branch `json:"branches"`
 	// 调用其它函数分支
@@ -261,8 +486,10 @@ type Branch struct {
 	Fallthrough bool `json:"fallthrough"`
 	// 是否异常分支
 	Throw bool `json:"throw"`
-
-	blockNo uint32
+	// 源块编号
+	SourceBlockID uint32 `json:"source_block_id,omitempty"`
+	// 目标块编号
+	DestinationBlockID uint32 `json:"destination_block_id,omitempty"`
 }
 
 // IntermediateText 输出中间文本形式
@@ -281,8 +508,11 @@ func (br *Branch) HumanReadableText(_ context.Context, i int, call bool) string
 		return fmt.Sprintf("call %4d returned %d\n", i, br.Count)
 	}
 	suffix := ""
-	if br.Fallthrough {
+	switch {
+	case br.Fallthrough:
 		suffix = " (fallthrough)"
+	case br.Throw:
+		suffix = " (throw)"
 	}
 	return fmt.Sprintf("branch %2d taken %d%s\n", i, br.Count, suffix)
 }
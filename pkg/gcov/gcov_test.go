@@ -0,0 +1,160 @@
+package gcov
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFile_HumanReadableText 测试 File.HumanReadableText 方法
+func TestFile_HumanReadableText(t *testing.T) {
+	a := assert.New(t)
+
+	content := []byte(`template<class T>
+T add(T a, T b) {
+  return a + b;
+}
+`)
+	f := File{
+		Filename: "add.h",
+		Functions: []Function{
+			{Name: "_Z3addIiET_S0_S0_", StartLine: 2, EndLine: 4, ExecutionCount: 2, ReturnCount: 2},
+			{Name: "_Z3addIdET_S0_S0_", StartLine: 2, EndLine: 4},
+		},
+		Lines: []Line{
+			{LineNumber: 2, Count: 2},
+			{LineNumber: 3, Count: 2},
+		},
+		Instances: []FunctionInstance{
+			{FunctionName: "_Z3addIiET_S0_S0_", Lines: []Line{{LineNumber: 2, Count: 2}, {LineNumber: 3, Count: 2}}},
+			{FunctionName: "_Z3addIdET_S0_S0_", Lines: []Line{{LineNumber: 2}, {LineNumber: 3}}},
+		},
+	}
+
+	a.Equal(`        -:    1:template<class T>
+        2:    2:T add(T a, T b) {
+        2:    3:  return a + b;
+        -:    4:}
+------------------
+_Z3addIiET_S0_S0_:
+function _Z3addIiET_S0_S0_ called 2 returned 100% blocks executed 0%
+        2:    2:T add(T a, T b) {
+        2:    3:  return a + b;
+        -:    4:}
+------------------
+_Z3addIdET_S0_S0_:
+function _Z3addIdET_S0_S0_ called 0 returned 0% blocks executed 0%
+        0:    2:T add(T a, T b) {
+        0:    3:  return a + b;
+        -:    4:}
+------------------
+`, f.HumanReadableText(t.Context(), content))
+}
+
+// TestParseVersion 测试 ParseVersion 方法
+func TestParseVersion(t *testing.T) {
+	a := assert.New(t)
+
+	v, err := ParseVersion("9")
+	a.NoError(err)
+	a.Equal(Version{Major: 9}, v)
+	v, err = ParseVersion("14.3.0")
+	a.NoError(err)
+	a.Equal(Version{Major: 14, Minor: 3}, v)
+	_, err = ParseVersion("gcc-9")
+	a.Error(err)
+	_, err = ParseVersion("9.5.x")
+	a.Error(err)
+	_, err = ParseVersion("9..1")
+	a.Error(err)
+	_, err = ParseVersion("9.-1")
+	a.Error(err)
+	_, err = ParseVersion("")
+	a.Error(err)
+}
+
+// TestVersion_CheckIntermediateFormat 测试 Version.CheckIntermediateFormat 方法
+func TestVersion_CheckIntermediateFormat(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError((&Version{Major: 8, Minor: 5}).CheckIntermediateFormat(false))
+	a.Error((&Version{Major: 8, Minor: 5}).CheckIntermediateFormat(true))
+	a.NoError((&Version{Major: 9}).CheckIntermediateFormat(true))
+	a.Error((&Version{Major: 9}).CheckIntermediateFormat(false))
+}
+
+// TestCoverageInfo_IntermediateText 测试 CoverageInfo.IntermediateText 方法
+func TestCoverageInfo_IntermediateText(t *testing.T) {
+	a := assert.New(t)
+
+	info := &CoverageInfo{
+		GCCVersion: Version{Major: 7, Minor: 5},
+		Files: []File{{
+			Filename:  "main.c",
+			Functions: []Function{{Name: "main", StartLine: 1, EndLine: 3, ExecutionCount: 1}},
+			Lines:     []Line{{LineNumber: 2, Count: 1}},
+		}},
+	}
+
+	a.Equal(`file:main.c
+function:1,1,main
+lcount:2,1
+`, info.IntermediateText(t.Context()))
+	a.Equal(`version:8.5.0
+file:main.c
+function:1,3,1,main
+lcount:2,1,0
+`, info.IntermediateText(ContextWithOutputGCCVersion(t.Context(), Version{Major: 8, Minor: 5})))
+}
+
+// TestCoverageInfo_IntermediateJSON 测试 CoverageInfo.IntermediateJSON 方法
+func TestCoverageInfo_IntermediateJSON(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	info := &CoverageInfo{
+		GCCVersion:    Version{Major: 15, Minor: 1},
+		FormatVersion: "2",
+		Files: []File{{
+			Filename: "main.c",
+			Functions: []Function{{
+				Name: "main", StartLine: 1, EndLine: 3, Blocks: 2, BlocksExecuted: 2, ExecutionCount: 1,
+				TotalPrimePaths: 1, CoveredPrimePaths: 1,
+				PrimePaths: []PrimePath{{ID: 0, Blocks: []uint32{0, 2, 1}, Covered: true}},
+			}},
+		}},
+	}
+
+	// 默认不包含质路径覆盖情况
+	content, err := info.IntermediateJSON(t.Context())
+	r.NoError(err)
+	a.JSONEq(`{
+  "gcc_version": "15.1.0",
+  "format_version": "2",
+  "files": [{
+    "file": "main.c",
+    "functions": [{
+      "name": "main", "start_line": 1, "end_line": 3,
+      "blocks": 2, "blocks_executed": 2, "execution_count": 1
+    }]
+  }]
+}`, string(content))
+
+	// 指定输出质路径覆盖情况
+	content, err = info.IntermediateJSON(ContextWithPrimePathsMode(t.Context(), PrimePathsSummary))
+	r.NoError(err)
+	a.JSONEq(`{
+  "gcc_version": "15.1.0",
+  "format_version": "2",
+  "files": [{
+    "file": "main.c",
+    "functions": [{
+      "name": "main", "start_line": 1, "end_line": 3,
+      "blocks": 2, "blocks_executed": 2, "execution_count": 1,
+      "total_prime_paths": 1, "covered_prime_paths": 1,
+      "prime_path_coverage": [{"id": 0, "sequence": [0, 2, 1], "covered": true}]
+    }]
+  }]
+}`, string(content))
+}
@@ -0,0 +1,74 @@
+package gcov
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// globCache 已编译的 glob 模式
+var globCache sync.Map
+
+// MatchGlob 判断路径是否匹配 glob 模式
+//
+// 支持 * （匹配除 / 外的任意字符）、 ? （匹配除 / 外的单个字符）、 [...] 字符集和 ** （匹配任意多级目录）。
+// 模式中不包含 / 时仅匹配路径的最后一个元素，比如 *.h 匹配任意目录下的头文件
+func MatchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		name = name[strings.LastIndex(name, "/")+1:]
+	}
+	return compileGlob(pattern).MatchString(name)
+}
+
+// compileGlob 将 glob 模式编译为正则表达式
+func compileGlob(pattern string) *regexp.Regexp {
+	if re, ok := globCache.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+
+	expr := &strings.Builder{}
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// **/ 匹配零或多级目录
+					i++
+					expr.WriteString("(?:.*/)?")
+				} else {
+					expr.WriteString(".*")
+				}
+			} else {
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			expr.WriteString("[^/]")
+		case '[':
+			j := strings.IndexByte(pattern[i:], ']')
+			if j < 0 {
+				expr.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			set := pattern[i+1 : i+j]
+			if strings.HasPrefix(set, "!") {
+				set = "^" + set[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(set, `\`, `\\`) + "]")
+			i += j
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		// 无效的字符集等，按字面匹配
+		re = regexp.MustCompile("^" + regexp.QuoteMeta(pattern) + "$")
+	}
+	globCache.Store(pattern, re)
+	return re
+}
@@ -0,0 +1,24 @@
+package gcov
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMatchGlob 测试 MatchGlob 方法
+func TestMatchGlob(t *testing.T) {
+	a := assert.New(t)
+
+	a.True(MatchGlob("*.h", "src/include/util.h"))
+	a.False(MatchGlob("*.h", "src/util.c"))
+	a.True(MatchGlob("src/*.c", "src/main.c"))
+	a.False(MatchGlob("src/*.c", "src/core/main.c"))
+	a.True(MatchGlob("src/**/*.c", "src/main.c"))
+	a.True(MatchGlob("src/**/*.c", "src/core/io/main.c"))
+	a.True(MatchGlob("src/**", "src/core/main.c"))
+	a.True(MatchGlob("/usr/**", "/usr/include/stdio.h"))
+	a.True(MatchGlob("test_?.[ch]", "test_a.h"))
+	a.False(MatchGlob("test_[!a].c", "test_a.c"))
+	a.True(MatchGlob("a+b(1).c", "a+b(1).c"))
+}
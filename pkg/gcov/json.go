@@ -0,0 +1,422 @@
+package gcov
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// formatVersion 返回指定 gcc 主版本号的 gcov JSON 中间格式版本
+func formatVersion(major int) string {
+	if major >= 14 {
+		return "2"
+	}
+	return "1"
+}
+
+var _ json.Marshaler = (*CoverageInfo)(nil)
+var _ json.Unmarshaler = (*CoverageInfo)(nil)
+
+// MarshalJSON 序列化为 JSON
+//
+// 输出字段与 GCCVersion 对应版本的 gcov JSON 中间格式一致，比如 gcc 14 及以上版本包含块编号和调用信息，
+// gcc 15 及以上版本始终包含质路径覆盖情况以免丢失数据
+func (info *CoverageInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(info.intermediateJSON(info.GCCVersion, true))
+}
+
+// UnmarshalJSON 从 gcov JSON 中间格式反序列化
+func (info *CoverageInfo) UnmarshalJSON(data []byte) error {
+	raw := &jsonCoverageInfo{}
+	if err := json.Unmarshal(data, raw); err != nil {
+		return err
+	}
+
+	*info = CoverageInfo{
+		GCCVersion:             raw.GCCVersion,
+		FormatVersion:          raw.FormatVersion,
+		DataFile:               raw.DataFile,
+		CurrenWorkingDirectory: raw.CurrenWorkingDirectory,
+		Files:                  make([]File, len(raw.Files)),
+	}
+	for i, f := range raw.Files {
+		info.Files[i] = File{
+			Filename:  f.Filename,
+			Functions: make([]Function, len(f.Functions)),
+			Lines:     make([]Line, len(f.Lines)),
+		}
+		for j, fn := range f.Functions {
+			info.Files[i].Functions[j] = fn.function()
+		}
+		for j, ln := range f.Lines {
+			info.Files[i].Lines[j] = ln.line()
+		}
+		info.Files[i].splitInstances()
+	}
+	return nil
+}
+
+// splitInstances 将起始于同一行的函数（比如 C++ 模板实例）各自输出的行拆分为 Instances ，并在 Lines 中汇总
+//
+// gcov JSON 中间格式中这些函数的行按函数分别输出，同一行号会出现多次
+func (f *File) splitInstances() {
+	startLines := map[uint32]int{}
+	for _, fn := range f.Functions {
+		startLines[fn.StartLine]++
+	}
+	grouped := map[string]bool{}
+	for _, fn := range f.Functions {
+		if startLines[fn.StartLine] > 1 {
+			grouped[fn.Name] = true
+		}
+	}
+	if len(grouped) == 0 {
+		return
+	}
+
+	for _, ln := range f.Lines {
+		if !grouped[ln.FunctionName] {
+			continue
+		}
+		i := slices.IndexFunc(f.Instances, func(inst FunctionInstance) bool {
+			return inst.FunctionName == ln.FunctionName
+		})
+		if i < 0 {
+			f.Instances = append(f.Instances, FunctionInstance{FunctionName: ln.FunctionName})
+			i = len(f.Instances) - 1
+		}
+		f.Instances[i].Lines = append(f.Instances[i].Lines, cloneLine(ln))
+	}
+	f.Lines = mergeLines(f.Lines)
+}
+
+// ParseJSONFile 解析 gcov JSON 中间格式文件
+//
+// 文件名以 .gz 结尾时按 gzip 压缩文件解析（比如 gcov --json-format 输出的 .gcov.json.gz 文件）
+func ParseJSONFile(fileName string) ([]*CoverageInfo, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("open json file %q error: %w", fileName, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var r io.Reader = bufio.NewReader(f)
+	if strings.HasSuffix(fileName, ".gz") {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("open gzip file %q error: %w", fileName, err)
+		}
+		defer func() { _ = gr.Close() }()
+		r = gr
+	}
+
+	ret, err := ParseJSON(r)
+	if err != nil {
+		return ret, fmt.Errorf("parse json file %q error: %w", fileName, err)
+	}
+	return ret, nil
+}
+
+// ParseJSON 解析 gcov JSON 中间格式
+//
+// 输入可以包含多个连续的 JSON 对象，比如 gcovgo -f json 输入多个文件时的输出
+func ParseJSON(r io.Reader) ([]*CoverageInfo, error) {
+	var ret []*CoverageInfo
+	decoder := json.NewDecoder(r)
+	for {
+		info := &CoverageInfo{}
+		if err := decoder.Decode(info); err != nil {
+			if errors.Is(err, io.EOF) {
+				return ret, nil
+			}
+			return ret, err
+		}
+		ret = append(ret, info)
+	}
+}
+
+// intermediateJSON 返回按指定 gcc 版本的 gcov JSON 中间格式组织的数据
+//
+// 指定版本与 GCCVersion 不同时，格式版本也按指定版本确定。 primePaths 为 true 时 gcc 15 及以上版本包含质路径覆盖情况
+func (info *CoverageInfo) intermediateJSON(version Version, primePaths bool) *jsonCoverageInfo {
+	ret := &jsonCoverageInfo{
+		GCCVersion:             version,
+		FormatVersion:          info.FormatVersion,
+		DataFile:               info.DataFile,
+		CurrenWorkingDirectory: info.CurrenWorkingDirectory,
+		Files:                  make([]jsonFile, len(info.Files)),
+	}
+	if version.Major != info.GCCVersion.Major {
+		ret.FormatVersion = formatVersion(version.Major)
+	}
+	for i, f := range info.Files {
+		ret.Files[i] = jsonFile{
+			Filename:  f.Filename,
+			Functions: make([]jsonFunction, len(f.Functions)),
+			Lines:     newJSONLines(version, &f),
+		}
+		for j, fn := range f.Functions {
+			ret.Files[i].Functions[j] = newJSONFunction(version, fn, primePaths)
+		}
+	}
+	return ret
+}
+
+// newJSONLines 创建指定 gcc 版本的 gcov JSON 中间格式的文件中各行覆盖情况信息
+//
+// 与 gcov 一致，起始于同一行的函数（比如 C++ 模板实例）在起始行处按函数顺序分别输出各自的行，不输出汇总的行
+func newJSONLines(version Version, f *File) []jsonLine {
+	instanceLines := make(map[string][]Line, len(f.Instances))
+	for _, inst := range f.Instances {
+		instanceLines[inst.FunctionName] = inst.Lines
+	}
+	var groups []Function
+	grouped := map[uint32]bool{}
+	for _, fn := range f.Functions {
+		lines, ok := instanceLines[fn.Name]
+		if !ok {
+			continue
+		}
+		groups = append(groups, fn)
+		for _, ln := range lines {
+			grouped[ln.LineNumber] = true
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].StartLine < groups[j].StartLine
+	})
+
+	ret := make([]jsonLine, 0, len(f.Lines))
+	groupI := 0
+	// 输出起始于指定行及之前的函数各自的行
+	appendGroups := func(lineNo uint32) {
+		for ; groupI < len(groups) && groups[groupI].StartLine <= lineNo; groupI++ {
+			for _, ln := range instanceLines[groups[groupI].Name] {
+				ln.FunctionName = groups[groupI].Name
+				ret = append(ret, newJSONLine(version, ln))
+			}
+		}
+	}
+	for _, ln := range f.Lines {
+		appendGroups(ln.LineNumber)
+		if !grouped[ln.LineNumber] {
+			ret = append(ret, newJSONLine(version, ln))
+		}
+	}
+	appendGroups(math.MaxUint32)
+	return ret
+}
+
+// jsonCoverageInfo gcov JSON 中间格式的覆盖情况信息
+type jsonCoverageInfo struct {
+	GCCVersion             Version    `json:"gcc_version"`
+	FormatVersion          string     `json:"format_version,omitempty"`
+	DataFile               string     `json:"data_file,omitempty"`
+	CurrenWorkingDirectory string     `json:"current_working_directory,omitempty"`
+	Files                  []jsonFile `json:"files"`
+}
+
+// jsonFile gcov JSON 中间格式的文件覆盖情况信息
+type jsonFile struct {
+	Filename  string         `json:"file"`
+	Functions []jsonFunction `json:"functions,omitempty"`
+	Lines     []jsonLine     `json:"lines,omitempty"`
+}
+
+// jsonFunction gcov JSON 中间格式的函数覆盖情况信息
+//
+// 指针类型的字段仅在对应 gcc 版本中输出
+type jsonFunction struct {
+	Name           string `json:"name"`
+	DemangledName  string `json:"demangled_name,omitempty"`
+	StartLine      uint32 `json:"start_line"`
+	StartColumn    uint32 `json:"start_column,omitempty"`
+	EndLine        uint32 `json:"end_line"`
+	EndColumn      uint32 `json:"end_column,omitempty"`
+	Blocks         uint32 `json:"blocks"`
+	BlocksExecuted uint32 `json:"blocks_executed"`
+	ExecutionCount uint64 `json:"execution_count"`
+
+	// gcc 15 及以上版本，且指定 --prime-paths 时
+	TotalPrimePaths   *uint32      `json:"total_prime_paths,omitempty"`
+	CoveredPrimePaths *uint32      `json:"covered_prime_paths,omitempty"`
+	PrimePaths        *[]PrimePath `json:"prime_path_coverage,omitempty"`
+}
+
+// newJSONFunction 创建指定 gcc 版本的 gcov JSON 中间格式的函数覆盖情况信息
+//
+// 与 gcov 一致，仅当 primePaths 为 true （对应 gcov --prime-paths ）时包含质路径覆盖情况
+func newJSONFunction(version Version, fn Function, primePaths bool) jsonFunction {
+	ret := jsonFunction{
+		Name:           fn.Name,
+		DemangledName:  fn.DemangledName,
+		StartLine:      fn.StartLine,
+		StartColumn:    fn.StartColumn,
+		EndLine:        fn.EndLine,
+		EndColumn:      fn.EndColumn,
+		Blocks:         fn.Blocks,
+		BlocksExecuted: fn.BlocksExecuted,
+		ExecutionCount: fn.ExecutionCount,
+	}
+	if primePaths && version.Major >= 15 {
+		paths := fn.PrimePaths
+		if paths == nil {
+			paths = []PrimePath{}
+		}
+		ret.TotalPrimePaths = &fn.TotalPrimePaths
+		ret.CoveredPrimePaths = &fn.CoveredPrimePaths
+		ret.PrimePaths = &paths
+	}
+	return ret
+}
+
+// function 返回对应的函数覆盖情况信息
+func (fn *jsonFunction) function() Function {
+	ret := Function{
+		Name:           fn.Name,
+		DemangledName:  fn.DemangledName,
+		StartLine:      fn.StartLine,
+		StartColumn:    fn.StartColumn,
+		EndLine:        fn.EndLine,
+		EndColumn:      fn.EndColumn,
+		Blocks:         fn.Blocks,
+		BlocksExecuted: fn.BlocksExecuted,
+		ExecutionCount: fn.ExecutionCount,
+	}
+	if fn.TotalPrimePaths != nil {
+		ret.TotalPrimePaths = *fn.TotalPrimePaths
+	}
+	if fn.CoveredPrimePaths != nil {
+		ret.CoveredPrimePaths = *fn.CoveredPrimePaths
+	}
+	if fn.PrimePaths != nil {
+		ret.PrimePaths = *fn.PrimePaths
+	}
+	return ret
+}
+
+// jsonLine gcov JSON 中间格式的行覆盖情况信息
+//
+// 指针类型的字段仅在对应 gcc 版本中输出
+type jsonLine struct {
+	LineNumber      uint32       `json:"line_number"`
+	FunctionName    string       `json:"function_name"`
+	Count           uint64       `json:"count"`
+	UnexecutedBlock bool         `json:"unexecuted_block"`
+	Branches        []jsonBranch `json:"branches"`
+
+	// gcc 14 及以上版本
+	BlockIDs   *[]uint32     `json:"block_ids,omitempty"`
+	Calls      *[]jsonCall   `json:"calls,omitempty"`
+	Conditions *[]jsonObject `json:"conditions,omitempty"`
+}
+
+// newJSONLine 创建指定 gcc 版本的 gcov JSON 中间格式的行覆盖情况信息
+func newJSONLine(version Version, ln Line) jsonLine {
+	ret := jsonLine{
+		LineNumber:      ln.LineNumber,
+		FunctionName:    ln.FunctionName,
+		Count:           ln.Count,
+		UnexecutedBlock: ln.UnexecutedBlock,
+		Branches:        make([]jsonBranch, len(ln.Branches)),
+	}
+	v2 := version.Major >= 14
+	for i, br := range ln.Branches {
+		ret.Branches[i] = jsonBranch{
+			Count:       br.Count,
+			Throw:       br.Throw,
+			Fallthrough: br.Fallthrough,
+		}
+		if v2 {
+			ret.Branches[i].SourceBlockID = &ln.Branches[i].SourceBlockID
+			ret.Branches[i].DestinationBlockID = &ln.Branches[i].DestinationBlockID
+		}
+	}
+	if v2 {
+		blockIDs := ln.BlockIDs
+		if blockIDs == nil {
+			blockIDs = []uint32{}
+		}
+		calls := make([]jsonCall, len(ln.CallBranches))
+		for i, call := range ln.CallBranches {
+			calls[i] = jsonCall{
+				SourceBlockID:      call.SourceBlockID,
+				DestinationBlockID: call.DestinationBlockID,
+				Returned:           call.Count,
+			}
+		}
+		conditions := []jsonObject{}
+		ret.BlockIDs = &blockIDs
+		ret.Calls = &calls
+		ret.Conditions = &conditions
+	}
+	return ret
+}
+
+// line 返回对应的行覆盖情况信息
+func (ln *jsonLine) line() Line {
+	ret := Line{
+		LineNumber:      ln.LineNumber,
+		FunctionName:    ln.FunctionName,
+		Count:           ln.Count,
+		UnexecutedBlock: ln.UnexecutedBlock,
+		Branches:        make([]Branch, len(ln.Branches)),
+	}
+	for i, br := range ln.Branches {
+		ret.Branches[i] = Branch{
+			Count:       br.Count,
+			Throw:       br.Throw,
+			Fallthrough: br.Fallthrough,
+		}
+		if br.SourceBlockID != nil {
+			ret.Branches[i].SourceBlockID = *br.SourceBlockID
+		}
+		if br.DestinationBlockID != nil {
+			ret.Branches[i].DestinationBlockID = *br.DestinationBlockID
+		}
+	}
+	if ln.BlockIDs != nil {
+		ret.BlockIDs = *ln.BlockIDs
+	}
+	if ln.Calls != nil {
+		for _, call := range *ln.Calls {
+			ret.CallBranches = append(ret.CallBranches, Branch{
+				Count:              call.Returned,
+				SourceBlockID:      call.SourceBlockID,
+				DestinationBlockID: call.DestinationBlockID,
+			})
+		}
+	}
+	return ret
+}
+
+// jsonBranch gcov JSON 中间格式的分支覆盖情况信息
+//
+// 指针类型的字段仅在对应 gcc 版本中输出
+type jsonBranch struct {
+	Count       uint64 `json:"count"`
+	Throw       bool   `json:"throw"`
+	Fallthrough bool   `json:"fallthrough"`
+
+	// gcc 14 及以上版本
+	SourceBlockID      *uint32 `json:"source_block_id,omitempty"`
+	DestinationBlockID *uint32 `json:"destination_block_id,omitempty"`
+}
+
+// jsonCall gcov JSON 中间格式的调用信息
+type jsonCall struct {
+	SourceBlockID      uint32 `json:"source_block_id"`
+	DestinationBlockID uint32 `json:"destination_block_id"`
+	Returned           uint64 `json:"returned"`
+}
+
+// jsonObject 任意 JSON 对象
+type jsonObject map[string]any
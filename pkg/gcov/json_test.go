@@ -0,0 +1,167 @@
+package gcov
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCoverageInfo_MarshalJSON 测试 CoverageInfo.MarshalJSON 方法
+func TestCoverageInfo_MarshalJSON(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	info := &CoverageInfo{
+		GCCVersion:    Version{Major: 13, Minor: 4},
+		FormatVersion: "1",
+		Files: []File{{
+			Filename:  "main.c",
+			Functions: []Function{{Name: "main", StartLine: 1, EndLine: 3, Blocks: 2, BlocksExecuted: 2, ExecutionCount: 1}},
+			Lines: []Line{{
+				LineNumber:   2,
+				Count:        1,
+				BlockIDs:     []uint32{2},
+				Branches:     []Branch{},
+				CallBranches: []Branch{{Count: 1, SourceBlockID: 2, DestinationBlockID: 1}},
+				FunctionName: "main",
+			}},
+		}},
+	}
+
+	// gcc 13
+	content, err := json.Marshal(info)
+	r.NoError(err)
+	a.JSONEq(`{
+  "gcc_version": "13.4.0",
+  "format_version": "1",
+  "files": [{
+    "file": "main.c",
+    "functions": [{
+      "name": "main", "start_line": 1, "end_line": 3,
+      "blocks": 2, "blocks_executed": 2, "execution_count": 1
+    }],
+    "lines": [{
+      "line_number": 2, "function_name": "main", "count": 1, "unexecuted_block": false,
+      "branches": []
+    }]
+  }]
+}`, string(content))
+
+	// gcc 14
+	info.GCCVersion = Version{Major: 14, Minor: 3}
+	info.FormatVersion = "2"
+	content, err = json.Marshal(info)
+	r.NoError(err)
+	a.JSONEq(`{
+  "gcc_version": "14.3.0",
+  "format_version": "2",
+  "files": [{
+    "file": "main.c",
+    "functions": [{
+      "name": "main", "start_line": 1, "end_line": 3,
+      "blocks": 2, "blocks_executed": 2, "execution_count": 1
+    }],
+    "lines": [{
+      "line_number": 2, "function_name": "main", "count": 1, "unexecuted_block": false,
+      "block_ids": [2],
+      "branches": [],
+      "calls": [{"source_block_id": 2, "destination_block_id": 1, "returned": 1}],
+      "conditions": []
+    }]
+  }]
+}`, string(content))
+}
+
+// TestCoverageInfo_MarshalJSON_Instances 测试 CoverageInfo.MarshalJSON 方法输出起始于同一行的函数
+//
+// 测试数据由 gcc 12 以 --coverage 编译 testdata/tpl/tpl.cpp 并执行生成，其中模板函数 add 有 int 和 double 两个实例，
+// tpl.gcov.json 为 gcov -b --json-format 的输出
+func TestCoverageInfo_MarshalJSON_Instances(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	info, err := ResolveBinaryFile("testdata/tpl/tpl.gcno", "testdata/tpl/tpl.gcda")
+	r.NoError(err)
+	content, err := json.Marshal(info)
+	r.NoError(err)
+	expected, err := os.ReadFile("testdata/tpl/tpl.gcov.json")
+	r.NoError(err)
+
+	// 各实例的行分别输出，与 gcov 一致
+	lines := func(content []byte) string {
+		raw := struct {
+			Files []struct {
+				Lines json.RawMessage `json:"lines"`
+			} `json:"files"`
+		}{}
+		r.NoError(json.Unmarshal(content, &raw))
+		r.Len(raw.Files, 1)
+		return string(raw.Files[0].Lines)
+	}
+	a.JSONEq(lines(expected), lines(content))
+
+	// 解析后各实例的行拆分到 Instances ，与解析 .gcno 和 .gcda 文件的结果一致
+	parsed, err := ParseJSONFile("testdata/tpl/tpl.gcov.json")
+	r.NoError(err)
+	r.Len(parsed, 1)
+	r.Len(parsed[0].Files, 1)
+	a.Equal(lineCounts(info.Files[0].Lines), lineCounts(parsed[0].Files[0].Lines))
+	r.Len(parsed[0].Files[0].Instances, 2)
+	for i, inst := range parsed[0].Files[0].Instances {
+		a.Equal(info.Files[0].Instances[i].FunctionName, inst.FunctionName)
+		a.Equal(lineCounts(info.Files[0].Instances[i].Lines), lineCounts(inst.Lines))
+	}
+}
+
+// lineCounts 返回各行行号、执行次数及各分支执行次数
+func lineCounts(lines []Line) [][]uint64 {
+	ret := make([][]uint64, len(lines))
+	for i, ln := range lines {
+		ret[i] = []uint64{uint64(ln.LineNumber), ln.Count}
+		for _, br := range ln.Branches {
+			ret[i] = append(ret[i], br.Count)
+		}
+	}
+	return ret
+}
+
+// TestParseJSON 测试 ParseJSON 方法
+func TestParseJSON(t *testing.T) {
+	r := require.New(t)
+	a := assert.New(t)
+
+	info := &CoverageInfo{
+		GCCVersion:    Version{Major: 14, Minor: 3},
+		FormatVersion: "2",
+		Files: []File{{
+			Filename:  "main.c",
+			Functions: []Function{{Name: "main", StartLine: 1, EndLine: 3, Blocks: 2, BlocksExecuted: 2, ExecutionCount: 1}},
+			Lines: []Line{{
+				LineNumber:   2,
+				Count:        1,
+				BlockIDs:     []uint32{2},
+				Branches:     []Branch{{Count: 1, SourceBlockID: 2, DestinationBlockID: 3}},
+				CallBranches: []Branch{{Count: 1, SourceBlockID: 2, DestinationBlockID: 1}},
+				FunctionName: "main",
+			}},
+		}},
+	}
+	content, err := json.Marshal(info)
+	r.NoError(err)
+
+	// 多个连续的 JSON 对象
+	infos, err := ParseJSON(strings.NewReader(string(content) + "\n" + string(content)))
+	r.NoError(err)
+	r.Len(infos, 2)
+	a.Equal(info, infos[0])
+	a.Equal(info, infos[1])
+
+	// gcov 输出的版本号
+	infos, err = ParseJSON(strings.NewReader(`{"gcc_version": "13.2.1 20231011", "files": []}`))
+	r.NoError(err)
+	a.Equal(Version{Major: 13, Minor: 2}, infos[0].GCCVersion)
+}
@@ -0,0 +1,216 @@
+package gcov
+
+import (
+	"path/filepath"
+	"slices"
+	"sort"
+)
+
+// Merge 合并多个覆盖情况信息，按规范化的源文件路径合并文件
+//
+// 同一源文件的同一行执行次数累加，分支和调用在数目一致时按顺序累加，否则按源块、目标块编号对应累加，无法对应时保留数目较多的一方；
+// 同名函数（比如多个编译单元中的内联函数）只保留一个，执行次数累加。
+// 所有输入的工作目录相同时，结果保留该工作目录，否则文件名均为绝对路径
+func Merge(infos ...*CoverageInfo) *CoverageInfo {
+	ret := &CoverageInfo{}
+	if len(infos) == 0 {
+		return ret
+	}
+
+	ret.GCCVersion = infos[0].GCCVersion
+	ret.FormatVersion = infos[0].FormatVersion
+	ret.CurrenWorkingDirectory = infos[0].CurrenWorkingDirectory
+	for _, info := range infos[1:] {
+		if info.CurrenWorkingDirectory != ret.CurrenWorkingDirectory {
+			ret.CurrenWorkingDirectory = ""
+		}
+		if info.GCCVersion.Major > ret.GCCVersion.Major ||
+			(info.GCCVersion.Major == ret.GCCVersion.Major && info.GCCVersion.Minor > ret.GCCVersion.Minor) {
+			ret.GCCVersion = info.GCCVersion
+			ret.FormatVersion = info.FormatVersion
+		}
+	}
+
+	files := map[string]int{}
+	for _, info := range infos {
+		for _, f := range info.Files {
+			name := filepath.Clean(info.SourcePath(f.Filename))
+			if ret.CurrenWorkingDirectory != "" {
+				name = RelativePath(ret.CurrenWorkingDirectory, name)
+			}
+			i, ok := files[name]
+			if !ok {
+				ret.Files = append(ret.Files, File{Filename: name})
+				i = len(ret.Files) - 1
+				files[name] = i
+			}
+			ret.Files[i].merge(&f)
+		}
+	}
+
+	sort.SliceStable(ret.Files, func(i, j int) bool { return ret.Files[i].Filename < ret.Files[j].Filename })
+	return ret
+}
+
+// merge 将另一文件的覆盖情况合并到该文件
+func (f *File) merge(o *File) {
+	for _, fn := range o.Functions {
+		i := slices.IndexFunc(f.Functions, func(cur Function) bool { return cur.Name == fn.Name })
+		if i < 0 {
+			f.Functions = append(f.Functions, cloneFunction(fn))
+			continue
+		}
+		f.Functions[i].merge(&fn)
+	}
+	sort.SliceStable(f.Functions, func(i, j int) bool { return f.Functions[i].StartLine < f.Functions[j].StartLine })
+
+	f.Lines = mergeLineSlices(f.Lines, o.Lines)
+
+	for _, inst := range o.Instances {
+		i := slices.IndexFunc(f.Instances, func(cur FunctionInstance) bool {
+			return cur.FunctionName == inst.FunctionName
+		})
+		if i < 0 {
+			f.Instances = append(f.Instances, FunctionInstance{
+				FunctionName: inst.FunctionName,
+				Lines:        mergeLineSlices(nil, inst.Lines),
+			})
+			continue
+		}
+		f.Instances[i].Lines = mergeLineSlices(f.Instances[i].Lines, inst.Lines)
+	}
+}
+
+// merge 将另一同名函数的覆盖情况合并到该函数
+func (fn *Function) merge(o *Function) {
+	fn.ExecutionCount += o.ExecutionCount
+	fn.ReturnCount += o.ReturnCount
+	fn.Blocks = max(fn.Blocks, o.Blocks)
+	fn.BlocksExecuted = max(fn.BlocksExecuted, o.BlocksExecuted)
+
+	if len(fn.PrimePaths) == len(o.PrimePaths) && fn.TotalPrimePaths == o.TotalPrimePaths {
+		fn.CoveredPrimePaths = 0
+		for i := range fn.PrimePaths {
+			fn.PrimePaths[i].Covered = fn.PrimePaths[i].Covered || o.PrimePaths[i].Covered
+			if fn.PrimePaths[i].Covered {
+				fn.CoveredPrimePaths++
+			}
+		}
+		if len(fn.PrimePaths) == 0 {
+			fn.CoveredPrimePaths = max(fn.CoveredPrimePaths, o.CoveredPrimePaths)
+		}
+	} else if o.CoveredPrimePaths > fn.CoveredPrimePaths {
+		fn.TotalPrimePaths = o.TotalPrimePaths
+		fn.CoveredPrimePaths = o.CoveredPrimePaths
+		fn.PrimePaths = slices.Clone(o.PrimePaths)
+	}
+}
+
+// cloneFunction 复制函数覆盖情况，避免合并时修改输入
+func cloneFunction(fn Function) Function {
+	fn.PrimePaths = slices.Clone(fn.PrimePaths)
+	return fn
+}
+
+// mergeLineSlices 按行号合并两组升序的行覆盖情况，返回升序的结果
+func mergeLineSlices(a, b []Line) []Line {
+	ret := make([]Line, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j >= len(b) || (i < len(a) && a[i].LineNumber < b[j].LineNumber):
+			ret = append(ret, a[i])
+			i++
+		case i >= len(a) || b[j].LineNumber < a[i].LineNumber:
+			ret = append(ret, cloneLine(b[j]))
+			j++
+		default:
+			ln := a[i]
+			ln.merge(&b[j])
+			ret = append(ret, ln)
+			i++
+			j++
+		}
+	}
+	return ret
+}
+
+// merge 将另一同一行的覆盖情况合并到该行
+func (ln *Line) merge(o *Line) {
+	ln.Count += o.Count
+	ln.UnexecutedBlock = ln.Count == 0 || (ln.UnexecutedBlock && o.UnexecutedBlock)
+	if ln.FunctionName == "" {
+		ln.FunctionName = o.FunctionName
+	}
+	ln.Branches = mergeBranches(ln.Branches, o.Branches)
+	ln.CallBranches = mergeBranches(ln.CallBranches, o.CallBranches)
+}
+
+// mergeBranches 合并两组分支
+//
+// 数目一致时按顺序累加执行次数。数目不一致（比如不同编译选项产生的目标文件）时，两组分支的源块、目标块编号均唯一则按编号对应，
+// 累加编号相同的分支的执行次数并保留只在一侧存在的分支；否则无法对应分支，返回数目较多的一组
+func mergeBranches(a, b []Branch) []Branch {
+	if len(a) == len(b) {
+		ret := slices.Clone(a)
+		for i := range ret {
+			ret[i].Count += b[i].Count
+		}
+		return ret
+	}
+
+	if ret, ok := mergeBranchesByBlockID(a, b); ok {
+		return ret
+	}
+	if len(b) > len(a) {
+		return slices.Clone(b)
+	}
+	return a
+}
+
+// branchKey 分支的源块、目标块编号
+type branchKey struct {
+	src, dst uint32
+}
+
+// mergeBranchesByBlockID 按源块、目标块编号合并两组分支，结果按编号排序，编号不唯一时返回 false
+func mergeBranchesByBlockID(a, b []Branch) ([]Branch, bool) {
+	indexes := make(map[branchKey]int, len(a)+len(b))
+	ret := make([]Branch, 0, len(a)+len(b))
+	for _, br := range a {
+		k := branchKey{src: br.SourceBlockID, dst: br.DestinationBlockID}
+		if _, ok := indexes[k]; ok {
+			return nil, false
+		}
+		indexes[k] = len(ret)
+		ret = append(ret, br)
+	}
+	seen := make(map[branchKey]bool, len(b))
+	for _, br := range b {
+		k := branchKey{src: br.SourceBlockID, dst: br.DestinationBlockID}
+		if seen[k] {
+			return nil, false
+		}
+		seen[k] = true
+		if i, ok := indexes[k]; ok {
+			ret[i].Count += br.Count
+			continue
+		}
+		ret = append(ret, br)
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].SourceBlockID != ret[j].SourceBlockID {
+			return ret[i].SourceBlockID < ret[j].SourceBlockID
+		}
+		return ret[i].DestinationBlockID < ret[j].DestinationBlockID
+	})
+	return ret, true
+}
+
+// cloneLine 复制行覆盖情况，避免合并时修改输入
+func cloneLine(ln Line) Line {
+	ln.Branches = slices.Clone(ln.Branches)
+	ln.CallBranches = slices.Clone(ln.CallBranches)
+	ln.BlockIDs = slices.Clone(ln.BlockIDs)
+	return ln
+}
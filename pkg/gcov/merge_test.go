@@ -0,0 +1,108 @@
+package gcov
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMerge 测试 Merge 方法
+func TestMerge(t *testing.T) {
+	a := assert.New(t)
+
+	info1 := &CoverageInfo{
+		GCCVersion:             Version{Major: 13},
+		CurrenWorkingDirectory: "/build",
+		Files: []File{
+			{
+				Filename:  "main.c",
+				Functions: []Function{{Name: "main", StartLine: 3, ExecutionCount: 1, Blocks: 4, BlocksExecuted: 4}},
+				Lines:     []Line{{LineNumber: 3, Count: 1, FunctionName: "main"}},
+			},
+			{
+				Filename:  "/src/util.h",
+				Functions: []Function{{Name: "inc", StartLine: 1, ExecutionCount: 2, Blocks: 3, BlocksExecuted: 2}},
+				Lines: []Line{
+					{LineNumber: 2, Count: 2, Branches: []Branch{{Count: 2}, {Count: 0}}, UnexecutedBlock: true},
+					{LineNumber: 3, Count: 0, UnexecutedBlock: true},
+				},
+			},
+		},
+	}
+	info2 := &CoverageInfo{
+		GCCVersion:             Version{Major: 14},
+		CurrenWorkingDirectory: "/build",
+		Files: []File{{
+			Filename:  "../src/./util.h",
+			Functions: []Function{{Name: "inc", StartLine: 1, ExecutionCount: 1, Blocks: 3, BlocksExecuted: 3}},
+			Lines: []Line{
+				{LineNumber: 1, Count: 1},
+				{LineNumber: 2, Count: 1, Branches: []Branch{{Count: 0}, {Count: 1}}},
+				{LineNumber: 3, Count: 1},
+			},
+		}},
+	}
+
+	merged := Merge(info1, info2)
+	a.Equal(&CoverageInfo{
+		GCCVersion:             Version{Major: 14},
+		CurrenWorkingDirectory: "/build",
+		Files: []File{
+			{
+				Filename:  "/src/util.h",
+				Functions: []Function{{Name: "inc", StartLine: 1, ExecutionCount: 3, Blocks: 3, BlocksExecuted: 3}},
+				Lines: []Line{
+					{LineNumber: 1, Count: 1},
+					{LineNumber: 2, Count: 3, Branches: []Branch{{Count: 2}, {Count: 1}}},
+					{LineNumber: 3, Count: 1},
+				},
+			},
+			{
+				Filename:  "main.c",
+				Functions: []Function{{Name: "main", StartLine: 3, ExecutionCount: 1, Blocks: 4, BlocksExecuted: 4}},
+				Lines:     []Line{{LineNumber: 3, Count: 1, FunctionName: "main"}},
+			},
+		},
+	}, merged)
+
+	// 不修改输入
+	a.Equal(uint64(2), info1.Files[1].Lines[0].Branches[0].Count)
+	a.Equal(uint64(2), info1.Files[1].Functions[0].ExecutionCount)
+}
+
+// TestMergeBranches 测试 mergeBranches 方法
+func TestMergeBranches(t *testing.T) {
+	a := assert.New(t)
+
+	// 数目一致时按顺序累加
+	a.Equal(
+		[]Branch{{Count: 3}, {Count: 1}},
+		mergeBranches([]Branch{{Count: 1}, {Count: 1}}, []Branch{{Count: 2}, {Count: 0}}),
+	)
+
+	// 数目不一致时按块编号对应
+	a.Equal(
+		[]Branch{
+			{Count: 3, SourceBlockID: 2, DestinationBlockID: 3, Fallthrough: true},
+			{Count: 1, SourceBlockID: 2, DestinationBlockID: 4},
+			{Count: 0, SourceBlockID: 2, DestinationBlockID: 7, Throw: true},
+		},
+		mergeBranches(
+			[]Branch{
+				{Count: 1, SourceBlockID: 2, DestinationBlockID: 3, Fallthrough: true},
+				{Count: 1, SourceBlockID: 2, DestinationBlockID: 4},
+			},
+			[]Branch{
+				{Count: 2, SourceBlockID: 2, DestinationBlockID: 3, Fallthrough: true},
+				{Count: 0, SourceBlockID: 2, DestinationBlockID: 4},
+				{Count: 0, SourceBlockID: 2, DestinationBlockID: 7, Throw: true},
+			},
+		),
+	)
+
+	// 没有块编号时无法对应，保留数目较多的一组
+	a.Equal(
+		[]Branch{{Count: 2}, {Count: 0}, {Count: 5}},
+		mergeBranches([]Branch{{Count: 1}, {Count: 1}}, []Branch{{Count: 2}, {Count: 0}, {Count: 5}}),
+	)
+}
@@ -1,17 +1,34 @@
 package gcov
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"runtime"
+	"slices"
 	"sort"
+	"sync"
 
 	"github.com/yhlooo/gcovgo/pkg/gcov/cfg"
 	"github.com/yhlooo/gcovgo/pkg/gcov/raw"
 )
 
+// ResolveOptions 解析选项
+type ResolveOptions struct {
+	// 是否包含编译器生成的函数（比如 C++ 静态初始化函数）
+	//
+	// 默认与 gcov 一致，忽略这些函数及其对应的行
+	IncludeArtificial bool
+}
+
 // ResolveBinaryFile 解析 gcov 二进制文件
 func ResolveBinaryFile(noteFileName, dataFileName string) (*CoverageInfo, error) {
+	return ResolveBinaryFileWithOptions(noteFileName, dataFileName, ResolveOptions{})
+}
+
+// ResolveBinaryFileWithOptions 使用指定选项解析 gcov 二进制文件
+func ResolveBinaryFileWithOptions(noteFileName, dataFileName string, opts ResolveOptions) (*CoverageInfo, error) {
 	noteFile, err := os.Open(noteFileName)
 	if err != nil {
 		return nil, fmt.Errorf("open note file %q error: %w", noteFileName, err)
@@ -28,11 +45,16 @@ func ResolveBinaryFile(noteFileName, dataFileName string) (*CoverageInfo, error)
 		dataReader = dataFile
 	}
 
-	return ResolveBinary(noteFile, dataReader)
+	return ResolveBinaryWithOptions(noteFile, dataReader, opts)
 }
 
 // ResolveBinary 解析 gcov 二进制
 func ResolveBinary(note, data io.Reader) (*CoverageInfo, error) {
+	return ResolveBinaryWithOptions(note, data, ResolveOptions{})
+}
+
+// ResolveBinaryWithOptions 使用指定选项解析 gcov 二进制
+func ResolveBinaryWithOptions(note, data io.Reader, opts ResolveOptions) (*CoverageInfo, error) {
 	// 反序列化 note
 	noteContent, err := io.ReadAll(note)
 	if err != nil {
@@ -47,7 +69,7 @@ func ResolveBinary(note, data io.Reader) (*CoverageInfo, error) {
 	}
 
 	// 反序列化 data ，获取计数器
-	var counters map[uint32][]uint64
+	var counters, pathsCounters map[uint32][]uint64
 	if data != nil {
 		dataContent, err := io.ReadAll(data)
 		if err != nil {
@@ -61,6 +83,7 @@ func ResolveBinary(note, data io.Reader) (*CoverageInfo, error) {
 			return nil, fmt.Errorf("not a valid data magic: %q", dataObj.Magic.String())
 		}
 		counters = dataObj.FunctionCounters()
+		pathsCounters = dataObj.FunctionPathsCounters()
 	}
 
 	major, minor, status := noteObj.Version.Parse()
@@ -70,15 +93,33 @@ func ResolveBinary(note, data io.Reader) (*CoverageInfo, error) {
 			Minor:  minor,
 			Status: status,
 		},
-		FormatVersion:          "1",
+		FormatVersion:          formatVersion(major),
 		CurrenWorkingDirectory: noteObj.CurrenWorkingDirectory,
 	}
-	filesMap := map[string]*File{}
+	fileIndexes := map[string]int{}
+	// getFile 获取指定文件覆盖情况，不存在时创建
+	//
+	// 因 ret.Files 扩容后会重新分配，不能长期持有返回的指针
+	getFile := func(fileName string) *File {
+		i, ok := fileIndexes[fileName]
+		if !ok {
+			ret.Files = append(ret.Files, File{Filename: fileName})
+			i = len(ret.Files) - 1
+			fileIndexes[fileName] = i
+		}
+		return &ret.Files[i]
+	}
+	// 各文件中定义的函数在该文件中的行覆盖情况，与 File.Functions 一一对应
+	functionLines := map[string][][]Line{}
+
 	functions := noteObj.FunctionNotes()
 	for _, fn := range functions {
 		if fn.Function == nil {
 			continue
 		}
+		if fn.Function.Artificial && !opts.IncludeArtificial {
+			continue
+		}
 
 		// 计算函数控制流图
 		blocks := len(fn.Arcs) + 1
@@ -105,14 +146,8 @@ func ResolveBinary(note, data io.Reader) (*CoverageInfo, error) {
 
 		// 记录函数覆盖信息
 		fileName := fn.Function.Source
-		f := filesMap[fileName]
-		if f == nil {
-			ret.Files = append(ret.Files, File{Filename: fileName})
-			f = &ret.Files[len(ret.Files)-1]
-			filesMap[fileName] = f
-		}
 
-		f.Functions = append(f.Functions, Function{
+		function := Function{
 			Name:           fn.Function.Name,
 			StartLine:      fn.Function.StartLineNo,
 			StartColumn:    fn.Function.StartColumn,
@@ -123,10 +158,20 @@ func ResolveBinary(note, data io.Reader) (*CoverageInfo, error) {
 			Blocks:         uint32(blocks) - 2,
 			BlocksExecuted: execBlocks,
 			DemangledName:  fn.Function.Name, // TODO: 应该不总是与 Name 相同，具体取值来源不确定
-		})
+			Artificial:     fn.Function.Artificial,
+		}
+		if fn.Paths != nil && fn.Paths.Num > 0 {
+			function.resolvePrimePaths(graph, fn.Paths.Num, pathsCounters[fn.Function.Ident])
+		}
 
-		// 记录行覆盖信息
+		// 先确保函数所在文件排在函数内联的其它文件之前
+		getFile(fn.Function.Source)
 
+		// 记录行覆盖信息
+		var lineFiles []string
+		lines := map[string][]Line{}
+		// 以各行作为最后一行的块，键依次为文件名、行号
+		lineBlocks := map[string]map[uint32][]*cfg.Block{}
 		for _, blkLines := range fn.Lines {
 			blk := graph.Get(blkLines.BlockNo)
 			if blk == nil {
@@ -134,50 +179,72 @@ func ResolveBinary(note, data io.Reader) (*CoverageInfo, error) {
 			}
 			for i, item := range blkLines.Lines {
 				if item.Filename != "" {
-					if item.Filename == fileName {
-						continue
-					}
 					// 切换文件
 					fileName = item.Filename
-					f = filesMap[fileName]
-					if f == nil {
-						ret.Files = append(ret.Files, File{Filename: fileName})
-						f = &ret.Files[len(ret.Files)-1]
-						filesMap[fileName] = f
-					}
 					continue
 				}
 
+				// 与 gcov 一致，块跨多个文件（比如 #include 到函数体中的代码）时，块中每个文件的最后一行均视为块的最后一行，
+				// 块及其出边对应的分支关联到这些行（不包括入口块和出口块）
+				blockEnd := blk.No() > 1 && (i == len(blkLines.Lines)-1 || blkLines.Lines[i+1].Filename != "")
+
+				// 记录以该行作为最后一行的块
+				if blockEnd {
+					if lineBlocks[fileName] == nil {
+						lineBlocks[fileName] = map[uint32][]*cfg.Block{}
+					}
+					lineBlocks[fileName][item.LineNo] = append(lineBlocks[fileName][item.LineNo], blk)
+				}
+
 				// 分支
-				call := false
+				// 与 gcov 一致，伪出边（被调用的函数异常退出或不返回）为调用，有伪出边的块为调用点；
+				// 仅有一条非伪出边时为无条件跳转，不视为分支；调用点的非直落出边为异常分支
 				branches := make([]Branch, 0)
-				if blkOut := blk.Out(); i == len(blkLines.Lines)-1 && len(blkOut) > 1 {
-					// 出边对应分支关联到块中最后一行
+				var callBranches []Branch
+				if blockEnd {
+					blkOut := blk.Out()
+					nonFake := 0
+					for _, arc := range blkOut {
+						if !arc.Flags().Fake() {
+							nonFake++
+						}
+					}
+					callSite := nonFake < len(blkOut)
 					for _, arc := range blkOut {
-						dstBlkNo := arc.Destination().No()
-						if dstBlkNo == 1 {
-							call = true
+						if arc.Flags().Fake() {
+							// 调用返回次数为块执行次数减去伪出边执行次数
+							returned := uint64(0)
+							if blk.Count() > arc.Count() {
+								returned = blk.Count() - arc.Count()
+							}
+							callBranches = append(callBranches, Branch{
+								Count:              returned,
+								SourceBlockID:      blk.No(),
+								DestinationBlockID: arc.Destination().No(),
+							})
+							continue
+						}
+						if nonFake < 2 {
 							continue
 						}
 						branches = append(branches, Branch{
-							Count:       arc.Count(),
-							Fallthrough: arc.Flags()&raw.ArcFlagFallthrough != 0,
-							Throw:       false, // TODO: ...
-							blockNo:     dstBlkNo,
+							Count:              arc.Count(),
+							Fallthrough:        arc.Flags().Fallthrough(),
+							Throw:              callSite && !arc.Flags().Fallthrough(),
+							SourceBlockID:      blk.No(),
+							DestinationBlockID: arc.Destination().No(),
 						})
 					}
 				}
 				sort.Slice(branches, func(i, j int) bool {
-					return branches[i].blockNo < branches[j].blockNo
+					return branches[i].DestinationBlockID < branches[j].DestinationBlockID
 				})
-				var callBranches []Branch
-				if call {
-					callBranches = branches
-					branches = make([]Branch, 0)
-				}
 
 				// 行
-				f.Lines = append(f.Lines, Line{
+				if _, ok := lines[fileName]; !ok {
+					lineFiles = append(lineFiles, fileName)
+				}
+				lines[fileName] = append(lines[fileName], Line{
 					LineNumber:      item.LineNo,
 					Count:           blk.Count(),
 					Branches:        branches,
@@ -187,38 +254,208 @@ func ResolveBinary(note, data io.Reader) (*CoverageInfo, error) {
 				})
 			}
 		}
+
+		var ownLines []Line
+		for _, name := range lineFiles {
+			fileLines := mergeLines(lines[name])
+			// 根据进入行的边计算执行次数
+			for i := range fileLines {
+				blks := lineBlocks[name][fileLines[i].LineNumber]
+				if len(blks) == 0 {
+					continue
+				}
+				fileLines[i].Count = cfg.LineCount(blks)
+				fileLines[i].BlockIDs = make([]uint32, len(blks))
+				for j, blk := range blks {
+					fileLines[i].BlockIDs[j] = blk.No()
+				}
+			}
+			if name == fn.Function.Source {
+				ownLines = fileLines
+			}
+			f := getFile(name)
+			f.Lines = append(f.Lines, fileLines...)
+		}
+
+		f := getFile(fn.Function.Source)
+		f.Functions = append(f.Functions, function)
+		functionLines[fn.Function.Source] = append(functionLines[fn.Function.Source], ownLines)
 	}
 
 	// 排序、去重
 	for fileI := range ret.Files {
-		sort.Slice(ret.Files[fileI].Functions, func(i, j int) bool {
-			return ret.Files[fileI].Functions[i].StartLine < ret.Files[fileI].Functions[j].StartLine
-		})
-		sort.SliceStable(ret.Files[fileI].Lines, func(i, j int) bool {
-			return ret.Files[fileI].Lines[i].LineNumber < ret.Files[fileI].Lines[j].LineNumber
-		})
+		f := &ret.Files[fileI]
+		fnLines := functionLines[f.Filename]
+		sort.Stable(functionsByStartLine{functions: f.Functions, lines: fnLines})
 		// 合并相同行
-		newLines := make([]Line, 0, len(ret.Files[fileI].Lines))
-		lastLineNo := uint32(0)
-		for _, line := range ret.Files[fileI].Lines {
-			if lastLineNo == 0 || line.LineNumber != lastLineNo {
-				// 不同行跳过
-				newLines = append(newLines, line)
-				lastLineNo = line.LineNumber
-				continue
-			}
+		f.Lines = mergeLines(f.Lines)
 
-			// 相同行合并
-			lastLine := &newLines[len(newLines)-1]
-			if line.Count > lastLine.Count {
-				lastLine.Count = line.Count
+		// 起始于同一行的函数（比如 C++ 模板实例）分别记录行覆盖情况
+		startLines := map[uint32]int{}
+		for _, function := range f.Functions {
+			startLines[function.StartLine]++
+		}
+		for i, function := range f.Functions {
+			if startLines[function.StartLine] > 1 {
+				f.Instances = append(f.Instances, FunctionInstance{
+					FunctionName: function.Name,
+					Lines:        fnLines[i],
+				})
 			}
-			lastLine.UnexecutedBlock = lastLine.UnexecutedBlock || line.UnexecutedBlock
-			lastLine.Branches = append(lastLine.Branches, line.Branches...)
-			lastLine.CallBranches = append(lastLine.CallBranches, line.CallBranches...)
 		}
-		ret.Files[fileI].Lines = newLines
 	}
 
 	return ret, nil
 }
+
+// mergeLines 按行号排序并合并相同行
+func mergeLines(lines []Line) []Line {
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].LineNumber < lines[j].LineNumber
+	})
+	newLines := make([]Line, 0, len(lines))
+	lastLineNo := uint32(0)
+	for _, line := range lines {
+		if lastLineNo == 0 || line.LineNumber != lastLineNo {
+			// 不同行跳过
+			newLines = append(newLines, line)
+			lastLineNo = line.LineNumber
+			continue
+		}
+
+		// 相同行合并
+		// 分支切片可能与函数实例的行共享底层数组，合并前先裁剪容量避免覆盖
+		lastLine := &newLines[len(newLines)-1]
+		lastLine.Count += line.Count
+		lastLine.UnexecutedBlock = lastLine.UnexecutedBlock || line.UnexecutedBlock
+		lastLine.BlockIDs = append(slices.Clip(lastLine.BlockIDs), line.BlockIDs...)
+		lastLine.Branches = append(slices.Clip(lastLine.Branches), line.Branches...)
+		lastLine.CallBranches = append(slices.Clip(lastLine.CallBranches), line.CallBranches...)
+	}
+	return newLines
+}
+
+// functionsByStartLine 按起始行号排序函数及其对应的行覆盖情况
+type functionsByStartLine struct {
+	functions []Function
+	lines     [][]Line
+}
+
+var _ sort.Interface = functionsByStartLine{}
+
+// Len 返回函数数目
+func (s functionsByStartLine) Len() int {
+	return len(s.functions)
+}
+
+// Less 比较函数起始行号
+func (s functionsByStartLine) Less(i, j int) bool {
+	return s.functions[i].StartLine < s.functions[j].StartLine
+}
+
+// Swap 交换函数及其对应的行覆盖情况
+func (s functionsByStartLine) Swap(i, j int) {
+	s.functions[i], s.functions[j] = s.functions[j], s.functions[i]
+	s.lines[i], s.lines[j] = s.lines[j], s.lines[i]
+}
+
+// primePathsLimit 从控制流图重建质路径时最多遍历的简单路径数目，与 gcc -fpath-coverage-limit 默认值一致
+const primePathsLimit = 250000
+
+// resolvePrimePaths 根据质路径数目和质路径计数器计算函数质路径覆盖情况
+func (fn *Function) resolvePrimePaths(graph cfg.CFG, num uint32, counts []uint64) {
+	paths, ok := graph.PrimePaths(primePathsLimit)
+	if !ok || len(paths) != int(num) {
+		// 重建的质路径与 note 中不一致，只记录是否覆盖
+		paths = nil
+	}
+
+	fn.TotalPrimePaths = num
+	fn.CoveredPrimePaths = 0
+	fn.PrimePaths = make([]PrimePath, num)
+	for i := uint32(0); i < num; i++ {
+		covered := false
+		if int(i/64) < len(counts) {
+			covered = counts[i/64]&(1<<(i%64)) != 0
+		}
+		if covered {
+			fn.CoveredPrimePaths++
+		}
+		fn.PrimePaths[i] = PrimePath{ID: i, Covered: covered}
+		if paths != nil {
+			fn.PrimePaths[i].Blocks = paths[i]
+		}
+	}
+}
+
+// BinaryFile 待解析的 gcov note 文件及对应的 data 文件
+type BinaryFile struct {
+	// gcov note 文件名
+	NoteFile string
+	// gcov data 文件名，为空时视为未执行
+	DataFile string
+}
+
+// ResolveResult 单个 gcov 二进制的解析结果
+type ResolveResult struct {
+	// 覆盖情况信息，解析失败时为 nil
+	Info *CoverageInfo
+	// 解析错误
+	Err error
+}
+
+// ResolveBinaryFiles 并发解析多个 gcov 二进制文件
+//
+// 最多同时解析 parallelism 个文件，不大于 0 时为 runtime.GOMAXPROCS(0) 。返回结果与 files 一一对应，
+// 单个文件解析失败不影响其它文件。上下文被取消时不再开始解析新的文件，并返回上下文的错误
+func ResolveBinaryFiles(
+	ctx context.Context,
+	files []BinaryFile,
+	opts ResolveOptions,
+	parallelism int,
+) ([]ResolveResult, error) {
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	parallelism = min(parallelism, len(files))
+
+	ret := make([]ResolveResult, len(files))
+	next := make(chan int)
+	wg := &sync.WaitGroup{}
+	for range parallelism {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				f := files[i]
+				info, err := ResolveBinaryFileWithOptions(f.NoteFile, f.DataFile, opts)
+				if err != nil {
+					ret[i].Err = fmt.Errorf("resolve %q error: %w", f.NoteFile, err)
+					continue
+				}
+				info.GcovNoteFile = f.NoteFile
+				info.GcovDataFile = f.DataFile
+				ret[i].Info = info
+			}
+		}()
+	}
+
+	var err error
+dispatch:
+	for i := range files {
+		// select 在多个分支就绪时随机选择，先检查上下文确保取消后不再分发
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break dispatch
+		case next <- i:
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	return ret, err
+}
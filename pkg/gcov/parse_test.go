@@ -0,0 +1,138 @@
+package gcov
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResolveBinaryFiles 测试 ResolveBinaryFiles 方法
+func TestResolveBinaryFiles(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	dir := t.TempDir()
+	files := make([]BinaryFile, 10)
+	for i := range files {
+		files[i] = BinaryFile{NoteFile: filepath.Join(dir, string(rune('a'+i))+".gcno")}
+	}
+
+	// 结果与输入一一对应
+	results, err := ResolveBinaryFiles(t.Context(), files, ResolveOptions{}, 3)
+	r.NoError(err)
+	r.Len(results, len(files))
+	for i, ret := range results {
+		a.Nil(ret.Info)
+		a.ErrorContains(ret.Err, files[i].NoteFile)
+	}
+
+	// 上下文取消
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	results, err = ResolveBinaryFiles(ctx, files, ResolveOptions{}, 0)
+	a.ErrorIs(err, context.Canceled)
+	// 取消后不再解析任何文件
+	for _, ret := range results {
+		a.Equal(ResolveResult{}, ret)
+	}
+}
+
+// TestResolveBinaryFile_Throw 测试 ResolveBinaryFile 方法解析调用点的异常分支
+//
+// 测试数据由 g++ 12 以 --coverage 编译 testdata/throw/throw.cpp 并执行生成
+func TestResolveBinaryFile_Throw(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	info, err := ResolveBinaryFile("testdata/throw/throw.gcno", "testdata/throw/throw.gcda")
+	r.NoError(err)
+	r.Len(info.Files, 1)
+	a.Equal("throw.cpp", info.Files[0].Filename)
+	lines := map[uint32]Line{}
+	for _, ln := range info.Files[0].Lines {
+		lines[ln.LineNumber] = ln
+	}
+
+	// 普通分支
+	r.Len(lines[4].Branches, 2)
+	a.False(lines[4].Branches[0].Throw)
+	a.False(lines[4].Branches[1].Throw)
+	a.Empty(lines[4].CallBranches)
+
+	// 调用点的直落分支和异常分支，与 gcov 一致
+	r.Len(lines[12].Branches, 2)
+	a.True(lines[12].Branches[0].Fallthrough)
+	a.False(lines[12].Branches[0].Throw)
+	a.Equal(uint64(0), lines[12].Branches[0].Count)
+	a.Equal(uint64(1), lines[12].Branches[1].Count)
+	a.True(lines[12].Branches[1].Throw)
+	r.Len(lines[12].CallBranches, 1)
+	a.Equal(uint32(1), lines[12].CallBranches[0].DestinationBlockID)
+	r.Len(lines[11].Branches, 2)
+	a.True(lines[11].Branches[1].Throw)
+
+	// 排除异常分支
+	ret := ExcludeBranches(t.Context(), BranchExclusionOptions{Throw: true}, info)
+	r.Len(ret, 1)
+	for _, ln := range ret[0].Files[0].Lines {
+		for _, br := range ln.Branches {
+			a.False(br.Throw, "line %d", ln.LineNumber)
+		}
+		if ln.LineNumber == 12 {
+			r.Len(ln.Branches, 1)
+			a.True(ln.Branches[0].Fallthrough)
+		}
+	}
+	// 不修改原覆盖情况信息
+	for _, ln := range info.Files[0].Lines {
+		if ln.LineNumber == 12 {
+			a.Len(ln.Branches, 2)
+		}
+	}
+}
+
+// TestResolveBinaryFile_BlockAcrossFiles 测试 ResolveBinaryFile 方法解析跨多个文件的块
+//
+// 测试数据由 gcc 12 以 --coverage 编译 testdata/span/span.c 并执行生成，其中块 3 依次包含 span.c 第 4 行、
+// body.h 第 1 行和 span.c 第 6 行， span.gcov.json 为 gcov -b --json-format 的输出
+func TestResolveBinaryFile_BlockAcrossFiles(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	info, err := ResolveBinaryFile("testdata/span/span.gcno", "testdata/span/span.gcda")
+	r.NoError(err)
+
+	// 各行执行次数和分支与 gcov 一致
+	expected, err := ParseJSONFile("testdata/span/span.gcov.json")
+	r.NoError(err)
+	r.Len(expected, 1)
+	lines := func(info *CoverageInfo) map[string][]Line {
+		ret := map[string][]Line{}
+		for _, f := range info.Files {
+			for _, ln := range f.Lines {
+				// gcc 12 的 JSON 中间格式不包含以下字段
+				ln.FunctionName = ""
+				ln.BlockIDs = nil
+				ln.CallBranches = nil
+				for i := range ln.Branches {
+					ln.Branches[i].SourceBlockID, ln.Branches[i].DestinationBlockID = 0, 0
+				}
+				ret[f.Filename] = append(ret[f.Filename], ln)
+			}
+		}
+		return ret
+	}
+	a.Equal(lines(expected[0]), lines(info))
+
+	// 块关联到每个文件中的最后一行
+	for _, f := range info.Files {
+		for _, ln := range f.Lines {
+			if f.Filename == "body.h" || (f.Filename == "span.c" && (ln.LineNumber == 4 || ln.LineNumber == 6)) {
+				a.Equal([]uint32{3}, ln.BlockIDs, "%s:%d", f.Filename, ln.LineNumber)
+			}
+		}
+	}
+}
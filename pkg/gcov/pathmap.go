@@ -0,0 +1,116 @@
+package gcov
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// PathMapping 路径映射规则，将 From 目录下的路径映射到 To 目录下
+type PathMapping struct {
+	From string
+	To   string
+}
+
+// ParsePathMapping 解析 FROM=TO 格式的路径映射规则
+func ParsePathMapping(s string) (PathMapping, error) {
+	from, to, ok := strings.Cut(s, "=")
+	if !ok || from == "" || to == "" {
+		return PathMapping{}, fmt.Errorf("invalid path mapping %q: must be in format FROM=TO", s)
+	}
+	return PathMapping{From: filepath.Clean(from), To: filepath.Clean(to)}, nil
+}
+
+// Map 映射路径，路径不在 From 目录下时返回 false
+func (m PathMapping) Map(name string) (string, bool) {
+	rel, ok := cutPathPrefix(name, m.From)
+	if !ok {
+		return name, false
+	}
+	return filepath.Join(m.To, rel), true
+}
+
+// PathOptions 源文件路径改写选项
+type PathOptions struct {
+	// 路径映射规则，按顺序使用第一个匹配的规则
+	Mappings []PathMapping
+	// 去掉的源文件路径前缀，与 gcov 的 -s 选项类似
+	SourcePrefix string
+	// 是否仅保留相对路径（去掉 SourcePrefix 后）的源文件，与 gcov 的 -r 选项类似
+	RelativeOnly bool
+}
+
+// Empty 判断选项是否不改写任何路径
+func (opts *PathOptions) Empty() bool {
+	return len(opts.Mappings) == 0 && opts.SourcePrefix == "" && !opts.RelativeOnly
+}
+
+// RewritePaths 返回按选项改写源文件路径的覆盖情况信息，不修改原覆盖情况信息
+//
+// 路径映射规则作用于工作目录和绝对路径的源文件名，相对路径的源文件名随工作目录映射。指定 SourcePrefix 时，
+// 位于该目录下的源文件名改为相对该目录的路径，其它源文件名改为绝对路径，并将工作目录设为该目录，
+// 使得 CoverageInfo.SourcePath 仍返回源文件实际所在的路径
+func (info *CoverageInfo) RewritePaths(opts *PathOptions) *CoverageInfo {
+	ret := *info
+	ret.CurrenWorkingDirectory = mapPath(opts.Mappings, info.CurrenWorkingDirectory)
+
+	prefix := ""
+	if opts.SourcePrefix != "" {
+		prefix = filepath.Clean(opts.SourcePrefix)
+		ret.CurrenWorkingDirectory = prefix
+	}
+
+	ret.Files = make([]File, 0, len(info.Files))
+	for _, f := range info.Files {
+		name := f.Filename
+		if filepath.IsAbs(name) || info.CurrenWorkingDirectory == "" {
+			name = mapPath(opts.Mappings, name)
+		}
+		relative := !filepath.IsAbs(name)
+
+		if prefix != "" {
+			// 先转为绝对路径，再去掉前缀
+			if !filepath.IsAbs(name) && info.CurrenWorkingDirectory != "" {
+				name = filepath.Join(mapPath(opts.Mappings, info.CurrenWorkingDirectory), name)
+			}
+			rel, ok := cutPathPrefix(name, prefix)
+			relative = ok
+			if ok {
+				name = rel
+			}
+		}
+
+		if opts.RelativeOnly && !relative {
+			continue
+		}
+		f.Filename = name
+		ret.Files = append(ret.Files, f)
+	}
+	return &ret
+}
+
+// mapPath 使用第一个匹配的规则映射路径，没有匹配的规则时返回原路径
+func mapPath(mappings []PathMapping, name string) string {
+	if name == "" {
+		return name
+	}
+	for _, m := range mappings {
+		if ret, ok := m.Map(name); ok {
+			return ret
+		}
+	}
+	return name
+}
+
+// cutPathPrefix 返回 name 相对 dir 的路径， name 不在 dir 下时返回 false
+func cutPathPrefix(name, dir string) (string, bool) {
+	name = filepath.Clean(name)
+	if name == dir {
+		return ".", true
+	}
+	if !strings.HasSuffix(dir, string(filepath.Separator)) {
+		dir += string(filepath.Separator)
+	}
+	rel, ok := strings.CutPrefix(name, dir)
+	return rel, ok
+}
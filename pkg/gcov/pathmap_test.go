@@ -0,0 +1,73 @@
+package gcov
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParsePathMapping 测试 ParsePathMapping 方法
+func TestParsePathMapping(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	m, err := ParsePathMapping("/build/=/home/me/repo")
+	r.NoError(err)
+	a.Equal(PathMapping{From: "/build", To: "/home/me/repo"}, m)
+
+	name, ok := m.Map("/build/src/main.c")
+	a.True(ok)
+	a.Equal("/home/me/repo/src/main.c", name)
+	_, ok = m.Map("/buildx/src/main.c")
+	a.False(ok)
+
+	for _, s := range []string{"/build", "=/repo", "/build="} {
+		_, err := ParsePathMapping(s)
+		a.Error(err, s)
+	}
+}
+
+// TestCoverageInfo_RewritePaths 测试 CoverageInfo.RewritePaths 方法
+func TestCoverageInfo_RewritePaths(t *testing.T) {
+	a := assert.New(t)
+
+	info := &CoverageInfo{
+		CurrenWorkingDirectory: "/build/out",
+		Files: []File{
+			{Filename: "../src/main.c"},
+			{Filename: "/build/include/util.h"},
+			{Filename: "/usr/include/stdio.h"},
+		},
+	}
+	names := func(info *CoverageInfo) []string {
+		var ret []string
+		for _, f := range info.Files {
+			ret = append(ret, f.Filename)
+		}
+		return ret
+	}
+	mappings := []PathMapping{{From: "/build", To: "/repo"}}
+
+	// 路径映射
+	ret := info.RewritePaths(&PathOptions{Mappings: mappings})
+	a.Equal("/repo/out", ret.CurrenWorkingDirectory)
+	a.Equal([]string{"../src/main.c", "/repo/include/util.h", "/usr/include/stdio.h"}, names(ret))
+	a.Equal("/repo/src/main.c", ret.SourcePath(ret.Files[0].Filename))
+
+	// 去掉前缀
+	ret = info.RewritePaths(&PathOptions{Mappings: mappings, SourcePrefix: "/repo/"})
+	a.Equal("/repo", ret.CurrenWorkingDirectory)
+	a.Equal([]string{"src/main.c", "include/util.h", "/usr/include/stdio.h"}, names(ret))
+	a.Equal("/repo/src/main.c", ret.SourcePath(ret.Files[0].Filename))
+
+	// 仅保留相对路径
+	ret = info.RewritePaths(&PathOptions{RelativeOnly: true})
+	a.Equal([]string{"../src/main.c"}, names(ret))
+	ret = info.RewritePaths(&PathOptions{SourcePrefix: "/build", RelativeOnly: true})
+	a.Equal([]string{"src/main.c", "include/util.h"}, names(ret))
+
+	// 不修改原覆盖情况信息
+	a.Equal("/build/out", info.CurrenWorkingDirectory)
+	a.Equal([]string{"../src/main.c", "/build/include/util.h", "/usr/include/stdio.h"}, names(info))
+}
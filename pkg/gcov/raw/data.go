@@ -10,8 +10,9 @@ type Data interface {
 
 // FunctionDataRecords data 中函数相关记录
 type FunctionDataRecords struct {
-	Function *RecordFunction
-	Counter  *RecordCounter
+	Function     *RecordFunction
+	Counter      *RecordCounter
+	PathsCounter *RecordCounter
 }
 
 var _ Data = (*Raw)(nil)
@@ -26,28 +27,34 @@ func (raw *Raw) FunctionsData() []FunctionDataRecords {
 	var functions []FunctionDataRecords
 
 	var (
-		funcRecord *RecordFunction
-		counter    *RecordCounter
+		funcRecord   *RecordFunction
+		counter      *RecordCounter
+		pathsCounter *RecordCounter
 	)
 	for _, record := range raw.Records {
 		switch record.Tag {
 		case TagFunction:
 			if funcRecord != nil {
 				functions = append(functions, FunctionDataRecords{
-					Function: funcRecord,
-					Counter:  counter,
+					Function:     funcRecord,
+					Counter:      counter,
+					PathsCounter: pathsCounter,
 				})
 			}
 			funcRecord = record.Function
 			counter = nil
+			pathsCounter = nil
 		case TagCounter:
 			counter = record.Counter
+		case TagPathsCounter:
+			pathsCounter = record.Counter
 		}
 	}
 	if funcRecord != nil {
 		functions = append(functions, FunctionDataRecords{
-			Function: funcRecord,
-			Counter:  counter,
+			Function:     funcRecord,
+			Counter:      counter,
+			PathsCounter: pathsCounter,
 		})
 	}
 
@@ -66,3 +73,18 @@ func (raw *Raw) FunctionCounters() map[uint32][]uint64 {
 	}
 	return counters
 }
+
+// FunctionPathsCounters 获取每个函数质路径计数器，键为函数 Ident ，值为计数器
+//
+// 计数器中每个值按位记录 64 条质路径是否被覆盖，第 i 条质路径对应第 i/64 个值的第 i%64 位
+func (raw *Raw) FunctionPathsCounters() map[uint32][]uint64 {
+	functions := raw.FunctionsData()
+	counters := make(map[uint32][]uint64, len(functions))
+	for _, fn := range functions {
+		if fn.Function == nil || fn.PathsCounter == nil {
+			continue
+		}
+		counters[fn.Function.Ident] = fn.PathsCounter.Counts
+	}
+	return counters
+}
@@ -14,6 +14,7 @@ type FunctionNoteRecords struct {
 	Blocks   *RecordBlocks
 	Arcs     []*RecordArcs
 	Lines    []*RecordLines
+	Paths    *RecordPaths
 }
 
 var _ Note = (*Raw)(nil)
@@ -32,6 +33,7 @@ func (raw *Raw) FunctionNotes() []FunctionNoteRecords {
 		blocks     *RecordBlocks
 		arcs       []*RecordArcs
 		lines      []*RecordLines
+		paths      *RecordPaths
 	)
 	for _, record := range raw.Records {
 		switch record.Tag {
@@ -42,18 +44,22 @@ func (raw *Raw) FunctionNotes() []FunctionNoteRecords {
 					Blocks:   blocks,
 					Arcs:     arcs,
 					Lines:    lines,
+					Paths:    paths,
 				})
 			}
 			funcRecord = record.Function
 			blocks = nil
 			arcs = nil
 			lines = nil
+			paths = nil
 		case TagBlocks:
 			blocks = record.Blocks
 		case TagArcs:
 			arcs = append(arcs, record.Arcs)
 		case TagLines:
 			lines = append(lines, record.Lines)
+		case TagPaths:
+			paths = record.Paths
 		}
 	}
 	if funcRecord != nil {
@@ -62,6 +68,7 @@ func (raw *Raw) FunctionNotes() []FunctionNoteRecords {
 			Blocks:   blocks,
 			Arcs:     arcs,
 			Lines:    lines,
+			Paths:    paths,
 		})
 	}
 
@@ -18,6 +18,8 @@ type RecordFunction struct {
 
 	// 函数名
 	Name string `json:",omitempty"`
+	// 是否编译器生成的函数（比如 C++ 静态初始化函数）
+	Artificial bool `json:",omitempty"`
 	// 函数所在文件名
 	Source string `json:",omitempty"`
 	// 函数起始行号
@@ -37,9 +39,11 @@ var _ encoding.BinaryUnmarshaler = (*RecordFunction)(nil)
 // note:
 //
 //	announce_function: header int32:ident int32:lineno_checksum
-//	    int32:cfg_checksum string:name string:source
+//	    int32:cfg_checksum string:name int32:artificial string:source
 //	    int32:start_lineno int32:start_column int32:end_lineno
 //
+// 其中 artificial 仅 gcc 8 及以上版本有
+//
 // data:
 //
 //	announce_function: header int32:ident int32:lineno_checksum int32:cfg_checksum
@@ -65,8 +69,14 @@ func (r *RecordFunction) UnmarshalBinary(data []byte) error {
 	}
 	data = data[n:]
 
-	// 文档中没有提及，但 name 和 source 之间可能有个 \x00000000
-	if len(data) > 4 && string(data[:4]) == "\x00\x00\x00\x00" {
+	if r.version >= Version8 {
+		if len(data) < 4 {
+			return newDataTooShortError(len(data), 4, "artificial")
+		}
+		r.Artificial = binary.LittleEndian.Uint32(data[:4]) != 0
+		data = data[4:]
+	} else if len(data) > 4 && string(data[:4]) == "\x00\x00\x00\x00" {
+		// 文档中没有提及，但 name 和 source 之间可能有个 \x00000000
 		data = data[4:]
 	}
 
@@ -0,0 +1,42 @@
+package raw
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRecordFunction_UnmarshalBinary 测试 RecordFunction.UnmarshalBinary 方法
+func TestRecordFunction_UnmarshalBinary(t *testing.T) {
+	a := assert.New(t)
+
+	data := binary.LittleEndian.AppendUint32(nil, 1) // ident
+	data = binary.LittleEndian.AppendUint32(data, 2) // lineno_checksum
+	data = binary.LittleEndian.AppendUint32(data, 3) // cfg_checksum
+	data = binary.LittleEndian.AppendUint32(data, 4) // name
+	data = append(data, "_GLOBAL__sub_I\x00\x00"...)
+	data = binary.LittleEndian.AppendUint32(data, 1) // artificial
+	data = binary.LittleEndian.AppendUint32(data, 2) // source
+	data = append(data, "a.cpp\x00\x00\x00"...)
+	data = binary.LittleEndian.AppendUint32(data, 10) // start_lineno
+	data = binary.LittleEndian.AppendUint32(data, 1)  // start_column
+	data = binary.LittleEndian.AppendUint32(data, 20) // end_lineno
+	data = binary.LittleEndian.AppendUint32(data, 2)  // end_column
+
+	r := RecordFunction{version: Version9}
+	a.NoError(r.UnmarshalBinary(data))
+	a.Equal(RecordFunction{
+		version:        Version9,
+		Ident:          1,
+		LineNoChecksum: 2,
+		CfgChecksum:    3,
+		Name:           "_GLOBAL__sub_I",
+		Artificial:     true,
+		Source:         "a.cpp",
+		StartLineNo:    10,
+		StartColumn:    1,
+		EndLineNo:      20,
+		EndColumn:      2,
+	}, r)
+}
@@ -0,0 +1,27 @@
+package raw
+
+import (
+	"encoding"
+	"encoding/binary"
+)
+
+// RecordPaths 质路径记录
+//
+// gcc 15 及以上版本使用 -fpath-coverage 编译时产生
+type RecordPaths struct {
+	// 函数中质路径数目
+	Num uint32
+}
+
+var _ encoding.BinaryUnmarshaler = (*RecordPaths)(nil)
+
+// UnmarshalBinary 从二进制反序列化
+//
+//	paths: header int32:num
+func (r *RecordPaths) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return newDataTooShortError(len(data), 4, "num")
+	}
+	r.Num = binary.LittleEndian.Uint32(data[:4])
+	return nil
+}
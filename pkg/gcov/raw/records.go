@@ -25,9 +25,11 @@ type Record struct {
 	Arcs *RecordArcs `json:",omitempty"`
 	// 行，当 Tag 为 TagLines 时有值
 	Lines *RecordLines `json:",omitempty"`
+	// 质路径，当 Tag 为 TagPaths 时有值
+	Paths *RecordPaths `json:",omitempty"`
 	// 程序摘要，当 Tag 为 TagProgramSummary 时有值
 	ProgramSummary *RecordProgramSummary `json:",omitempty"`
-	// 计数器，当 Tag 为 TagCounter 时有值
+	// 计数器，当 Tag 为 TagCounter 或 TagPathsCounter 时有值
 	Counter *RecordCounter `json:",omitempty"`
 	// 原始数据，当 Tag 无法处理时有值
 	Raw *RecordRaw `json:",omitempty"`
@@ -62,10 +64,13 @@ func (r *Record) UnmarshalBinary(data []byte) error {
 	case TagLines:
 		r.Lines = &RecordLines{version: r.version}
 		recordData = r.Lines
+	case TagPaths:
+		r.Paths = &RecordPaths{}
+		recordData = r.Paths
 	case TagProgramSummary:
 		r.ProgramSummary = &RecordProgramSummary{}
 		recordData = r.ProgramSummary
-	case TagCounter:
+	case TagCounter, TagPathsCounter:
 		r.Counter = &RecordCounter{}
 		recordData = r.Counter
 	default:
@@ -102,8 +107,13 @@ const (
 	TagBlocks   RecordTag = 0x01410000
 	TagArcs     RecordTag = 0x01430000
 	TagLines    RecordTag = 0x01450000
+	TagPaths    RecordTag = 0x01490000
 	TagCounter  RecordTag = 0x01a10000
 
+	// 质路径计数器，即 GCOV_TAG_FOR_COUNTER(GCOV_COUNTER_PATHS)
+	// 计数器按位记录各质路径是否被覆盖
+	TagPathsCounter RecordTag = 0x01b30000
+
 	// Note 的记录类型
 	// 以 [41..9f] 开头
 
@@ -128,8 +138,12 @@ func (tag RecordTag) String() string {
 		return "Arcs"
 	case TagLines:
 		return "Lines"
+	case TagPaths:
+		return "Paths"
 	case TagCounter:
 		return "Counter"
+	case TagPathsCounter:
+		return "PathsCounter"
 	case TagObjectSummary:
 		return "ObjectSummary"
 	case TagProgramSummary:
@@ -0,0 +1,159 @@
+package gcov
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// Summary 覆盖率统计
+type Summary struct {
+	// 可执行行数
+	LinesFound int `json:"lines_found"`
+	// 有执行的行数
+	LinesHit int `json:"lines_hit"`
+	// 函数数
+	FunctionsFound int `json:"functions_found"`
+	// 有执行的函数数
+	FunctionsHit int `json:"functions_hit"`
+	// 分支数
+	BranchesFound int `json:"branches_found"`
+	// 有执行的分支数
+	BranchesHit int `json:"branches_hit"`
+	// 基本块数
+	BlocksFound int `json:"blocks_found"`
+	// 有执行的基本块数
+	BlocksHit int `json:"blocks_hit"`
+}
+
+// Add 累加统计数据
+func (s *Summary) Add(o Summary) {
+	s.LinesFound += o.LinesFound
+	s.LinesHit += o.LinesHit
+	s.FunctionsFound += o.FunctionsFound
+	s.FunctionsHit += o.FunctionsHit
+	s.BranchesFound += o.BranchesFound
+	s.BranchesHit += o.BranchesHit
+	s.BlocksFound += o.BlocksFound
+	s.BlocksHit += o.BlocksHit
+}
+
+// LinePercent 返回行覆盖率百分比，没有可执行行时返回 100
+func (s Summary) LinePercent() float64 {
+	return Percent(s.LinesHit, s.LinesFound)
+}
+
+// FunctionPercent 返回函数覆盖率百分比，没有函数时返回 100
+func (s Summary) FunctionPercent() float64 {
+	return Percent(s.FunctionsHit, s.FunctionsFound)
+}
+
+// BranchPercent 返回分支覆盖率百分比，没有分支时返回 100
+func (s Summary) BranchPercent() float64 {
+	return Percent(s.BranchesHit, s.BranchesFound)
+}
+
+// Percent 返回百分比，总数为 0 时返回 100
+func Percent(hit, found int) float64 {
+	if found == 0 {
+		return 100
+	}
+	return float64(hit) * 100 / float64(found)
+}
+
+// Summary 统计文件覆盖率
+//
+// 函数执行次数不为 0 时视为有执行，基本块数按 Function.Blocks 和 Function.BlocksExecuted 累加
+func (f *File) Summary() Summary {
+	ret := Summary{FunctionsFound: len(f.Functions)}
+	for _, fn := range f.Functions {
+		if fn.ExecutionCount > 0 {
+			ret.FunctionsHit++
+		}
+		ret.BlocksFound += int(fn.Blocks)
+		ret.BlocksHit += int(fn.BlocksExecuted)
+	}
+	for _, ln := range f.Lines {
+		ret.LinesFound++
+		if ln.Count > 0 {
+			ret.LinesHit++
+		}
+		ret.BranchesFound += len(ln.Branches)
+		for _, br := range ln.Branches {
+			if br.Count > 0 {
+				ret.BranchesHit++
+			}
+		}
+	}
+	return ret
+}
+
+// Summary 统计所有文件的覆盖率
+func (info *CoverageInfo) Summary() Summary {
+	ret := Summary{}
+	for i := range info.Files {
+		ret.Add(info.Files[i].Summary())
+	}
+	return ret
+}
+
+// SummaryReport 按文件、目录和整体汇总的覆盖率统计
+type SummaryReport struct {
+	// 整体统计
+	Total Summary `json:"total"`
+	// 各目录统计，按目录名排序
+	Directories []DirectorySummary `json:"directories"`
+	// 各文件统计，按文件名排序
+	Files []FileSummary `json:"files"`
+}
+
+// DirectorySummary 目录覆盖率统计
+type DirectorySummary struct {
+	// 目录名
+	Directory string `json:"directory"`
+	Summary
+}
+
+// FileSummary 文件覆盖率统计
+type FileSummary struct {
+	// 文件名
+	Filename string `json:"file"`
+	Summary
+}
+
+// Summarize 按文件、目录和整体汇总覆盖率统计
+//
+// 文件名为源文件路径，位于 root 下的源文件使用相对 root 的路径。同一文件出现多次（比如被多个目标文件包含的头文件）时，
+// 先按 Merge 合并后再统计，每个文件只统计一次
+func Summarize(root string, infos ...*CoverageInfo) *SummaryReport {
+	ret := &SummaryReport{
+		Directories: []DirectorySummary{},
+		Files:       []FileSummary{},
+	}
+	merged := Merge(infos...)
+	dirs := map[string]int{}
+	for i := range merged.Files {
+		f := &merged.Files[i]
+		name := filepath.ToSlash(RelativePath(root, merged.SourcePath(f.Filename)))
+		s := f.Summary()
+		ret.Files = append(ret.Files, FileSummary{Filename: name, Summary: s})
+		ret.Total.Add(s)
+
+		dir := path.Dir(name)
+		j, ok := dirs[dir]
+		if !ok {
+			ret.Directories = append(ret.Directories, DirectorySummary{Directory: dir})
+			j = len(ret.Directories) - 1
+			dirs[dir] = j
+		}
+		ret.Directories[j].Add(s)
+	}
+
+	sort.SliceStable(ret.Directories, func(i, j int) bool {
+		return ret.Directories[i].Directory < ret.Directories[j].Directory
+	})
+	sort.SliceStable(ret.Files, func(i, j int) bool {
+		return ret.Files[i].Filename < ret.Files[j].Filename
+	})
+	return ret
+}
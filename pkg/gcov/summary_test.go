@@ -0,0 +1,82 @@
+package gcov
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSummarize 测试 Summarize 方法
+func TestSummarize(t *testing.T) {
+	a := assert.New(t)
+
+	info := &CoverageInfo{
+		CurrenWorkingDirectory: "/workdir",
+		Files: []File{
+			{
+				Filename: "src/main.c",
+				Functions: []Function{
+					{Name: "main", ExecutionCount: 1, Blocks: 5, BlocksExecuted: 4},
+					{Name: "unused", Blocks: 3},
+				},
+				Lines: []Line{
+					{LineNumber: 1, Count: 1},
+					{LineNumber: 2, Count: 1, Branches: []Branch{{Count: 1}, {Count: 0}}},
+					{LineNumber: 3},
+				},
+			},
+			{
+				Filename: "src/util.c",
+				Lines:    []Line{{LineNumber: 1, Count: 2}},
+			},
+			{
+				Filename: "/usr/include/stdio.h",
+				Lines:    []Line{{LineNumber: 10}},
+			},
+		},
+	}
+
+	main := Summary{
+		LinesFound: 3, LinesHit: 2,
+		FunctionsFound: 2, FunctionsHit: 1,
+		BranchesFound: 2, BranchesHit: 1,
+		BlocksFound: 8, BlocksHit: 4,
+	}
+	a.Equal(main, info.Files[0].Summary())
+
+	total := main
+	total.LinesFound += 2
+	total.LinesHit++
+	a.Equal(total, info.Summary())
+
+	src := main
+	src.LinesFound++
+	src.LinesHit++
+	report := Summarize("/workdir", info)
+	a.Equal(total, report.Total)
+	a.Equal([]DirectorySummary{
+		{Directory: "/usr/include", Summary: Summary{LinesFound: 1}},
+		{Directory: "src", Summary: src},
+	}, report.Directories)
+	a.Equal([]string{"/usr/include/stdio.h", "src/main.c", "src/util.c"}, []string{
+		report.Files[0].Filename, report.Files[1].Filename, report.Files[2].Filename,
+	})
+
+	// 同一文件出现多次时合并统计
+	other := &CoverageInfo{
+		CurrenWorkingDirectory: "/workdir/build",
+		Files: []File{{
+			Filename: "/usr/include/stdio.h",
+			Lines:    []Line{{LineNumber: 10, Count: 3}, {LineNumber: 11}},
+		}},
+	}
+	report = Summarize("/workdir", info, other)
+	a.Len(report.Files, 3)
+	a.Equal("/usr/include/stdio.h", report.Files[0].Filename)
+	a.Equal(Summary{LinesFound: 2, LinesHit: 1}, report.Files[0].Summary)
+	a.Equal(total.LinesFound+1, report.Total.LinesFound)
+	a.Equal(total.LinesHit+1, report.Total.LinesHit)
+
+	a.Equal(float64(100), Summary{}.LinePercent())
+	a.InDelta(66.67, main.LinePercent(), 0.01)
+}
@@ -0,0 +1 @@
+s *= 2;
@@ -0,0 +1,14 @@
+int f(int n) {
+  int s = 0;
+  for (int i = 0; i < n; i++) {
+    s += i;
+#include "body.h"
+    if (s > 10)
+      s -= 1;
+  }
+  return s;
+}
+
+int main(void) {
+  return f(5) > 0 ? 0 : 1;
+}
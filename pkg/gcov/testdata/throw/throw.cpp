@@ -0,0 +1,17 @@
+#include <stdexcept>
+
+int check(int v) {
+  if (v < 0)
+    throw std::invalid_argument("negative");
+  return v;
+}
+
+int main(int argc, char **argv) {
+  try {
+    check(argc);
+    check(-argc);
+  } catch (const std::exception &) {
+    return 1;
+  }
+  return 0;
+}
@@ -0,0 +1,12 @@
+template <typename T>
+T add(T a, T b) {
+  if (a > b)
+    return a + b;
+  return b;
+}
+
+int main() {
+  int x = add(1, 2);
+  double y = add(2.0, 1.0);
+  return x + y > 0 ? 0 : 1;
+}
@@ -0,0 +1,111 @@
+package gcov
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Metric 覆盖率指标
+type Metric string
+
+// Metric 的可选值
+const (
+	MetricLine     Metric = "line"
+	MetricBranch   Metric = "branch"
+	MetricFunction Metric = "function"
+)
+
+// Percent 返回统计数据中指定指标的覆盖率百分比
+func (s Summary) Percent(metric Metric) float64 {
+	switch metric {
+	case MetricBranch:
+		return s.BranchPercent()
+	case MetricFunction:
+		return s.FunctionPercent()
+	default:
+		return s.LinePercent()
+	}
+}
+
+// Threshold 覆盖率阈值
+type Threshold struct {
+	// 指标
+	Metric Metric
+	// 文件路径 glob 模式，为空时表示整体覆盖率阈值
+	Pattern string
+	// 最低覆盖率百分比
+	Percent float64
+}
+
+// ParseThreshold 解析覆盖率阈值
+//
+// 格式为 PERCENT 或 GLOB=PERCENT ，前者为整体覆盖率阈值，后者为匹配 GLOB 的每个文件的覆盖率阈值
+func ParseThreshold(metric Metric, s string) (Threshold, error) {
+	ret := Threshold{Metric: metric}
+	value := s
+	if i := strings.LastIndex(s, "="); i >= 0 {
+		ret.Pattern = s[:i]
+		value = s[i+1:]
+		if ret.Pattern == "" {
+			return ret, fmt.Errorf("invalid %s coverage threshold %q: empty pattern", metric, s)
+		}
+	}
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return ret, fmt.Errorf("invalid %s coverage threshold %q: %w", metric, s, err)
+	}
+	if percent < 0 || percent > 100 {
+		return ret, fmt.Errorf("invalid %s coverage threshold %q: out of range [0, 100]", metric, s)
+	}
+	ret.Percent = percent
+	return ret, nil
+}
+
+// ThresholdViolation 未达到覆盖率阈值的情况
+type ThresholdViolation struct {
+	Threshold
+	// 未达到阈值的文件名，为空时表示整体覆盖率
+	Filename string
+	// 实际覆盖率百分比
+	Actual float64
+}
+
+// String 返回描述文本
+func (v ThresholdViolation) String() string {
+	target := "total"
+	if v.Filename != "" {
+		target = v.Filename
+	}
+	ret := fmt.Sprintf("%s coverage of %s is %.2f%%, below %.2f%%", v.Metric, target, v.Actual, v.Percent)
+	if v.Pattern != "" {
+		ret += fmt.Sprintf(" required by %q", v.Pattern)
+	}
+	return ret
+}
+
+// CheckThresholds 检查覆盖率是否达到阈值，返回未达到阈值的情况
+//
+// 整体阈值未达到时，同时列出覆盖率低于该阈值的各文件；文件阈值对匹配模式的每个文件分别检查。
+// report 由 Summarize 生成时，同一源文件在多个覆盖情况信息中的覆盖情况已合并，按合并后的覆盖率检查
+func CheckThresholds(report *SummaryReport, thresholds []Threshold) []ThresholdViolation {
+	var ret []ThresholdViolation
+	for _, th := range thresholds {
+		if th.Pattern == "" {
+			actual := report.Total.Percent(th.Metric)
+			if actual >= th.Percent {
+				continue
+			}
+			ret = append(ret, ThresholdViolation{Threshold: th, Actual: actual})
+		}
+		for _, f := range report.Files {
+			if th.Pattern != "" && !MatchGlob(th.Pattern, f.Filename) {
+				continue
+			}
+			if actual := f.Percent(th.Metric); actual < th.Percent {
+				ret = append(ret, ThresholdViolation{Threshold: th, Filename: f.Filename, Actual: actual})
+			}
+		}
+	}
+	return ret
+}
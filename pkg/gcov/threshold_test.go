@@ -0,0 +1,71 @@
+package gcov
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseThreshold 测试 ParseThreshold 方法
+func TestParseThreshold(t *testing.T) {
+	a := assert.New(t)
+
+	th, err := ParseThreshold(MetricLine, "80")
+	a.NoError(err)
+	a.Equal(Threshold{Metric: MetricLine, Percent: 80}, th)
+	th, err = ParseThreshold(MetricBranch, "src/core/**=62.5%")
+	a.NoError(err)
+	a.Equal(Threshold{Metric: MetricBranch, Pattern: "src/core/**", Percent: 62.5}, th)
+	_, err = ParseThreshold(MetricLine, "=80")
+	a.Error(err)
+	_, err = ParseThreshold(MetricLine, "120")
+	a.Error(err)
+}
+
+// TestCheckThresholds 测试 CheckThresholds 方法
+func TestCheckThresholds(t *testing.T) {
+	a := assert.New(t)
+
+	report := &SummaryReport{
+		Total: Summary{LinesFound: 10, LinesHit: 7, BranchesFound: 4, BranchesHit: 4},
+		Files: []FileSummary{
+			{Filename: "src/core/a.c", Summary: Summary{LinesFound: 5, LinesHit: 5, BranchesFound: 4, BranchesHit: 4}},
+			{Filename: "src/util/b.c", Summary: Summary{LinesFound: 5, LinesHit: 2}},
+		},
+	}
+
+	a.Empty(CheckThresholds(report, []Threshold{
+		{Metric: MetricLine, Percent: 70},
+		{Metric: MetricBranch, Percent: 100},
+		{Metric: MetricLine, Pattern: "src/core/**", Percent: 100},
+	}))
+
+	violations := CheckThresholds(report, []Threshold{
+		{Metric: MetricLine, Percent: 80},
+		{Metric: MetricLine, Pattern: "src/**", Percent: 50},
+	})
+	if !a.Len(violations, 3) {
+		return
+	}
+	a.Equal([]string{
+		"line coverage of total is 70.00%, below 80.00%",
+		"line coverage of src/util/b.c is 40.00%, below 80.00%",
+		`line coverage of src/util/b.c is 40.00%, below 50.00% required by "src/**"`,
+	}, []string{violations[0].String(), violations[1].String(), violations[2].String()})
+}
+
+// TestCheckThresholds_Aggregated 测试 CheckThresholds 方法对多次出现的同一文件按合并后的覆盖率检查
+func TestCheckThresholds_Aggregated(t *testing.T) {
+	a := assert.New(t)
+
+	// 头文件在一个目标文件中未执行，在另一个目标文件中全部执行
+	header := func(count uint64) *CoverageInfo {
+		return &CoverageInfo{Files: []File{{
+			Filename: "/src/util.h",
+			Lines:    []Line{{LineNumber: 1, Count: count}, {LineNumber: 2, Count: count}},
+		}}}
+	}
+	report := Summarize("/src", header(0), header(3))
+	a.Len(report.Files, 1)
+	a.Empty(CheckThresholds(report, []Threshold{{Metric: MetricLine, Pattern: "*.h", Percent: 100}}))
+}
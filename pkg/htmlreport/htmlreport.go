@@ -0,0 +1,270 @@
+package htmlreport
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+)
+
+// Options 报告生成选项
+type Options struct {
+	// 报告标题
+	Title string
+	// 源文件根目录，位于该目录下的源文件使用相对该目录的路径
+	Root string
+	// 报告生成时间
+	Timestamp time.Time
+}
+
+// Generate 在 dir 目录生成静态 HTML 覆盖率报告
+//
+// 生成 index.html 索引页，包含各目录和各文件的行、函数和分支覆盖率，以及每个源文件的带执行次数标注的源码页。
+// 同一源文件出现在多个覆盖情况信息中（比如被多个目标文件包含的头文件）时，先按 gcov.Merge 合并，每个源文件只生成一个源码页。
+// 读取源文件失败时源码页仅包含行号和执行次数
+func Generate(ctx context.Context, dir string, opts Options, infos ...*gcov.CoverageInfo) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	if opts.Title == "" {
+		opts.Title = "Coverage Report"
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("make directory %q error: %w", dir, err)
+	}
+
+	idx := &indexPage{
+		Title:     opts.Title,
+		Timestamp: opts.Timestamp.Format(time.RFC3339),
+	}
+	dirs := map[string]*dirEntry{}
+	// 索引页名称不可用作源码页
+	pageNames := map[string]bool{"index.html": true}
+	info := gcov.Merge(infos...)
+	for i := range info.Files {
+		f := &info.Files[i]
+		sourcePath := info.SourcePath(f.Filename)
+		name := filepath.ToSlash(gcov.RelativePath(opts.Root, sourcePath))
+
+		entry := fileEntry{
+			Name:    path.Base(name),
+			Path:    name,
+			Page:    uniquePageName(pageNames, name),
+			Summary: f.Summary(),
+		}
+
+		content, err := os.ReadFile(sourcePath)
+		if err != nil {
+			logger.Info(fmt.Sprintf("WARN: read file %q error: %v", sourcePath, err))
+		}
+		if err := writePage(
+			filepath.Join(dir, entry.Page), fileTemplate,
+			newFilePage(opts.Title, &entry, f, content),
+		); err != nil {
+			return err
+		}
+
+		dirName := path.Dir(name)
+		d, ok := dirs[dirName]
+		if !ok {
+			d = &dirEntry{Name: dirName}
+			dirs[dirName] = d
+		}
+		d.Files = append(d.Files, entry)
+		d.Summary.Add(entry.Summary)
+		idx.Summary.Add(entry.Summary)
+	}
+
+	dirNames := make([]string, 0, len(dirs))
+	for name := range dirs {
+		dirNames = append(dirNames, name)
+	}
+	sort.Strings(dirNames)
+	for _, name := range dirNames {
+		d := dirs[name]
+		sort.SliceStable(d.Files, func(i, j int) bool { return d.Files[i].Name < d.Files[j].Name })
+		idx.Dirs = append(idx.Dirs, *d)
+	}
+
+	return writePage(filepath.Join(dir, "index.html"), indexTemplate, idx)
+}
+
+// indexPage 索引页数据
+type indexPage struct {
+	Title     string
+	Timestamp string
+	Summary   gcov.Summary
+	Dirs      []dirEntry
+}
+
+// dirEntry 索引页中的目录
+type dirEntry struct {
+	Name    string
+	Summary gcov.Summary
+	Files   []fileEntry
+}
+
+// fileEntry 索引页中的文件
+type fileEntry struct {
+	Name    string
+	Path    string
+	Page    string
+	Summary gcov.Summary
+}
+
+// filePage 源码页数据
+type filePage struct {
+	Title     string
+	File      *fileEntry
+	Functions []functionRow
+	Lines     []lineRow
+}
+
+// functionRow 源码页中的函数
+type functionRow struct {
+	Name           string
+	StartLine      uint32
+	ExecutionCount uint64
+}
+
+// lineRow 源码页中的行
+type lineRow struct {
+	Number uint32
+	// 是否可执行行
+	Executable bool
+	Count      uint64
+	// 覆盖状态，用于着色，可能为 covered 、 partial 、 uncovered 或空
+	Status   string
+	Source   string
+	Branches []branchCell
+}
+
+// branchCell 源码页中的分支
+type branchCell struct {
+	Taken bool
+	Title string
+}
+
+// newFilePage 创建源码页数据
+func newFilePage(title string, entry *fileEntry, f *gcov.File, content []byte) *filePage {
+	page := &filePage{Title: title, File: entry}
+	for _, fn := range f.Functions {
+		name := fn.DemangledName
+		if name == "" {
+			name = fn.Name
+		}
+		page.Functions = append(page.Functions, functionRow{
+			Name:           name,
+			StartLine:      fn.StartLine,
+			ExecutionCount: fn.ExecutionCount,
+		})
+	}
+
+	var sourceLines []string
+	if len(content) > 0 {
+		sourceLines = strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	}
+	linesN := len(sourceLines)
+	if len(f.Lines) > 0 && int(f.Lines[len(f.Lines)-1].LineNumber) > linesN {
+		linesN = int(f.Lines[len(f.Lines)-1].LineNumber)
+	}
+	page.Lines = make([]lineRow, linesN)
+	for i := range page.Lines {
+		page.Lines[i].Number = uint32(i + 1)
+		if i < len(sourceLines) {
+			page.Lines[i].Source = sourceLines[i]
+		}
+	}
+	for _, ln := range f.Lines {
+		if ln.LineNumber == 0 {
+			continue
+		}
+		row := &page.Lines[ln.LineNumber-1]
+		row.Executable = true
+		row.Count += ln.Count
+		taken := 0
+		for i, br := range ln.Branches {
+			cell := branchCell{Taken: br.Count > 0}
+			switch {
+			case ln.Count == 0:
+				cell.Title = fmt.Sprintf("Branch %d not executed", i)
+			case br.Count > 0:
+				cell.Title = fmt.Sprintf("Branch %d taken %d time(s)", i, br.Count)
+				taken++
+			default:
+				cell.Title = fmt.Sprintf("Branch %d never taken", i)
+			}
+			if br.Throw {
+				cell.Title += " (throw)"
+			}
+			row.Branches = append(row.Branches, cell)
+		}
+		switch {
+		case row.Count == 0:
+			row.Status = "uncovered"
+		case taken < len(ln.Branches):
+			row.Status = "partial"
+		case row.Status == "":
+			row.Status = "covered"
+		}
+	}
+
+	return page
+}
+
+var pageNameInvalidChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// uniquePageName 返回源文件对应的源码页文件名，与已使用的文件名重复时添加序号
+func uniquePageName(used map[string]bool, name string) string {
+	base := strings.Trim(pageNameInvalidChars.ReplaceAllString(name, "_"), "_")
+	page := base + ".html"
+	for i := 1; used[page]; i++ {
+		page = fmt.Sprintf("%s.%d.html", base, i)
+	}
+	used[page] = true
+	return page
+}
+
+// writePage 渲染页面并写入文件
+func writePage(fileName string, tmpl *template.Template, data any) error {
+	f, err := os.OpenFile(fileName, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open file %q error: %w", fileName, err)
+	}
+	defer func() { _ = f.Close() }()
+	if err := tmpl.Execute(f, data); err != nil {
+		return fmt.Errorf("render %q error: %w", fileName, err)
+	}
+	return nil
+}
+
+// percent 返回百分比文本
+func percent(hit, found int) string {
+	if found == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(hit)*100/float64(found))
+}
+
+// level 返回覆盖率等级，用于着色
+func level(hit, found int) string {
+	switch {
+	case found == 0:
+		return "none"
+	case hit*100 >= found*90:
+		return "high"
+	case hit*100 >= found*75:
+		return "medium"
+	default:
+		return "low"
+	}
+}
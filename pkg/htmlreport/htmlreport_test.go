@@ -0,0 +1,121 @@
+package htmlreport
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+)
+
+// TestGenerate 测试 Generate 方法
+func TestGenerate(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	srcDir := t.TempDir()
+	r.NoError(os.MkdirAll(filepath.Join(srcDir, "src"), 0o755))
+	r.NoError(os.WriteFile(filepath.Join(srcDir, "src", "main.c"), []byte(`int main(int argc) {
+  if (argc > 1 && argc < 5)
+    return 1;
+  return 0;
+}
+`), 0o644))
+
+	info := &gcov.CoverageInfo{
+		CurrenWorkingDirectory: srcDir,
+		Files: []gcov.File{{
+			Filename:  "src/main.c",
+			Functions: []gcov.Function{{Name: "main", StartLine: 1, EndLine: 5, ExecutionCount: 1}},
+			Lines: []gcov.Line{
+				{LineNumber: 1, Count: 1},
+				{LineNumber: 2, Count: 1, Branches: []gcov.Branch{{Count: 1}, {Count: 0}, {Count: 1}, {Count: 0}}},
+				{LineNumber: 3, Count: 0},
+				{LineNumber: 4, Count: 1},
+			},
+		}},
+	}
+
+	outDir := filepath.Join(t.TempDir(), "report")
+	r.NoError(Generate(t.Context(), outDir, Options{Root: srcDir, Timestamp: time.Unix(0, 0)}, info))
+
+	index, err := os.ReadFile(filepath.Join(outDir, "index.html"))
+	r.NoError(err)
+	a.Contains(string(index), `<title>Coverage Report</title>`)
+	a.Contains(string(index), `<tr class="dir"><td>src</td><td class="num medium">75.0%</td><td class="num">3 / 4</td>`)
+	a.Contains(string(index), `<a href="src_main.c.html">main.c</a>`)
+
+	page, err := os.ReadFile(filepath.Join(outDir, "src_main.c.html"))
+	r.NoError(err)
+	a.Contains(string(page), `<tr id="L2" class="partial"><td class="lineno"><a href="#L2">2</a></td><td class="count">1</td>`+
+		`<td class="branches"><span class="taken" title="Branch 0 taken 1 time(s)">+</span>`+
+		`<span class="nottaken" title="Branch 1 never taken">-</span>`)
+	a.Contains(string(page), `<tr id="L3" class="uncovered">`)
+	a.Contains(string(page), `<td class="src">  if (argc &gt; 1 &amp;&amp; argc &lt; 5)</td>`)
+	a.Contains(string(page), `<tr id="L5" class=""><td class="lineno"><a href="#L5">5</a></td><td class="count"></td>`)
+}
+
+// TestGenerate_Merge 测试 Generate 方法合并多个覆盖情况信息中的同一源文件
+func TestGenerate_Merge(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	srcDir := t.TempDir()
+	header := func(cwd string, count uint64) *gcov.CoverageInfo {
+		return &gcov.CoverageInfo{
+			CurrenWorkingDirectory: filepath.Join(srcDir, cwd),
+			Files: []gcov.File{{
+				Filename: "../include/util.h",
+				Lines:    []gcov.Line{{LineNumber: 1, Count: count}, {LineNumber: 2}},
+			}},
+		}
+	}
+
+	outDir := filepath.Join(t.TempDir(), "report")
+	r.NoError(Generate(
+		t.Context(), outDir, Options{Root: srcDir, Timestamp: time.Unix(0, 0)},
+		header("a", 0), header("b", 2),
+	))
+
+	entries, err := os.ReadDir(outDir)
+	r.NoError(err)
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	a.Equal([]string{"include_util.h.html", "index.html"}, names)
+
+	index, err := os.ReadFile(filepath.Join(outDir, "index.html"))
+	r.NoError(err)
+	a.Contains(string(index), `<tr class="dir"><td>include</td><td class="num low">50.0%</td><td class="num">1 / 2</td>`)
+
+	page, err := os.ReadFile(filepath.Join(outDir, "include_util.h.html"))
+	r.NoError(err)
+	a.Contains(string(page), `<tr id="L1" class="covered"><td class="lineno"><a href="#L1">1</a></td><td class="count">2</td>`)
+}
+
+// TestGenerate_IndexPageName 测试 Generate 方法生成与索引页同名的源码页
+func TestGenerate_IndexPageName(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	srcDir := t.TempDir()
+	info := &gcov.CoverageInfo{
+		CurrenWorkingDirectory: srcDir,
+		Files:                  []gcov.File{{Filename: "index", Lines: []gcov.Line{{LineNumber: 1, Count: 1}}}},
+	}
+
+	outDir := filepath.Join(t.TempDir(), "report")
+	r.NoError(Generate(t.Context(), outDir, Options{Root: srcDir, Timestamp: time.Unix(0, 0)}, info))
+
+	index, err := os.ReadFile(filepath.Join(outDir, "index.html"))
+	r.NoError(err)
+	a.Contains(string(index), `<a href="index.1.html">index</a>`)
+	page, err := os.ReadFile(filepath.Join(outDir, "index.1.html"))
+	r.NoError(err)
+	a.Contains(string(page), `<tr id="L1" class="covered">`)
+}
@@ -0,0 +1,100 @@
+package htmlreport
+
+import (
+	"html/template"
+)
+
+// funcs 模板函数
+var funcs = template.FuncMap{
+	"percent": percent,
+	"level":   level,
+}
+
+// style 页面样式
+const style = `<style>
+body { font-family: sans-serif; margin: 1em 2em; color: #222; }
+h1 { font-size: 1.4em; }
+table { border-collapse: collapse; }
+th, td { padding: 2px 8px; text-align: left; }
+table.summary td, table.summary th, table.index td, table.index th { border: 1px solid #ccc; }
+table.index tr.dir td { background: #eee; font-weight: bold; }
+td.num { text-align: right; font-family: monospace; }
+td.high { background: #b5f0b5; }
+td.medium { background: #f7e9a5; }
+td.low { background: #f6b3b3; }
+table.source { font-family: monospace; white-space: pre; width: 100%; }
+table.source td { padding: 0 6px; }
+table.source td.lineno { color: #888; text-align: right; }
+table.source td.count { text-align: right; }
+tr.covered td.count, tr.covered td.src { background: #dff6df; }
+tr.partial td.count, tr.partial td.src { background: #f9efc3; }
+tr.uncovered td.count, tr.uncovered td.src { background: #f9d3d3; }
+span.taken { color: #080; }
+span.nottaken { color: #c00; }
+a { color: #036; }
+</style>`
+
+// summaryTemplate 覆盖率统计表格
+const summaryTemplate = `{{define "summary"}}<table class="summary">
+<tr><th></th><th>Hit</th><th>Total</th><th>Coverage</th></tr>
+<tr><td>Lines</td><td class="num">{{.LinesHit}}</td><td class="num">{{.LinesFound}}</td><td class="num {{level .LinesHit .LinesFound}}">{{percent .LinesHit .LinesFound}}</td></tr>
+<tr><td>Functions</td><td class="num">{{.FunctionsHit}}</td><td class="num">{{.FunctionsFound}}</td><td class="num {{level .FunctionsHit .FunctionsFound}}">{{percent .FunctionsHit .FunctionsFound}}</td></tr>
+<tr><td>Branches</td><td class="num">{{.BranchesHit}}</td><td class="num">{{.BranchesFound}}</td><td class="num {{level .BranchesHit .BranchesFound}}">{{percent .BranchesHit .BranchesFound}}</td></tr>
+</table>{{end}}
+{{- define "rates"}}<td class="num {{level .LinesHit .LinesFound}}">{{percent .LinesHit .LinesFound}}</td><td class="num">{{.LinesHit}} / {{.LinesFound}}</td><td class="num {{level .FunctionsHit .FunctionsFound}}">{{percent .FunctionsHit .FunctionsFound}}</td><td class="num">{{.FunctionsHit}} / {{.FunctionsFound}}</td><td class="num {{level .BranchesHit .BranchesFound}}">{{percent .BranchesHit .BranchesFound}}</td><td class="num">{{.BranchesHit}} / {{.BranchesFound}}</td>{{end}}`
+
+// indexTemplate 索引页模板
+var indexTemplate = template.Must(template.New("index").Funcs(funcs).Parse(summaryTemplate + `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+` + style + `
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Generated at {{.Timestamp}}</p>
+{{template "summary" .Summary}}
+<h2>Files</h2>
+<table class="index">
+<tr><th>File</th><th colspan="2">Lines</th><th colspan="2">Functions</th><th colspan="2">Branches</th></tr>
+{{- range .Dirs}}
+<tr class="dir"><td>{{.Name}}</td>{{template "rates" .Summary}}</tr>
+{{- range .Files}}
+<tr><td><a href="{{.Page}}">{{.Name}}</a></td>{{template "rates" .Summary}}</tr>
+{{- end}}
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// fileTemplate 源码页模板
+var fileTemplate = template.Must(template.New("file").Funcs(funcs).Parse(summaryTemplate + `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.File.Path}} - {{.Title}}</title>
+` + style + `
+</head>
+<body>
+<h1><a href="index.html">{{.Title}}</a> - {{.File.Path}}</h1>
+{{template "summary" .File.Summary}}
+{{- if .Functions}}
+<h2>Functions</h2>
+<table class="index">
+<tr><th>Function</th><th>Line</th><th>Calls</th></tr>
+{{- range .Functions}}
+<tr><td><a href="#L{{.StartLine}}">{{.Name}}</a></td><td class="num">{{.StartLine}}</td><td class="num {{if .ExecutionCount}}high{{else}}low{{end}}">{{.ExecutionCount}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+<h2>Source</h2>
+<table class="source">
+{{- range .Lines}}
+<tr id="L{{.Number}}" class="{{.Status}}"><td class="lineno"><a href="#L{{.Number}}">{{.Number}}</a></td><td class="count">{{if .Executable}}{{.Count}}{{end}}</td><td class="branches">{{range .Branches}}<span class="{{if .Taken}}taken{{else}}nottaken{{end}}" title="{{.Title}}">{{if .Taken}}+{{else}}-{{end}}</span>{{end}}</td><td class="src">{{.Source}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
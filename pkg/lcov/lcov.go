@@ -0,0 +1,82 @@
+package lcov
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+)
+
+// Text 以 LCOV 跟踪文件（ .info ）格式输出覆盖情况信息
+//
+// 多个覆盖情况信息输出到同一跟踪文件中，每个文件对应一条以 SF 开始、 end_of_record 结束的记录
+func Text(ctx context.Context, infos ...*gcov.CoverageInfo) string {
+	ret := &strings.Builder{}
+	for _, info := range infos {
+		for _, f := range info.Files {
+			ret.WriteString(FileText(ctx, info.SourcePath(f.Filename), &f))
+		}
+	}
+	return ret.String()
+}
+
+// FileText 以 LCOV 跟踪文件格式输出单个文件的覆盖情况记录
+func FileText(_ context.Context, path string, f *gcov.File) string {
+	ret := &strings.Builder{}
+	_, _ = fmt.Fprintf(ret, "SF:%s\n", path)
+
+	// 函数
+	fnHit := 0
+	for _, fn := range f.Functions {
+		_, _ = fmt.Fprintf(ret, "FN:%d,%s\n", fn.StartLine, fn.Name)
+	}
+	for _, fn := range f.Functions {
+		_, _ = fmt.Fprintf(ret, "FNDA:%d,%s\n", fn.ExecutionCount, fn.Name)
+		if fn.ExecutionCount > 0 {
+			fnHit++
+		}
+	}
+	_, _ = fmt.Fprintf(ret, "FNF:%d\nFNH:%d\n", len(f.Functions), fnHit)
+
+	// 分支
+	brFound, brHit := 0, 0
+	for _, ln := range f.Lines {
+		block := -1
+		branch := 0
+		lastSrc := uint32(0)
+		for i, br := range ln.Branches {
+			if i == 0 || br.SourceBlockID != lastSrc {
+				block++
+				branch = 0
+				lastSrc = br.SourceBlockID
+			}
+			taken := "-"
+			if ln.Count > 0 {
+				taken = fmt.Sprintf("%d", br.Count)
+			}
+			_, _ = fmt.Fprintf(ret, "BRDA:%d,%d,%d,%s\n", ln.LineNumber, block, branch, taken)
+			branch++
+			brFound++
+			if br.Count > 0 {
+				brHit++
+			}
+		}
+	}
+	if brFound > 0 {
+		_, _ = fmt.Fprintf(ret, "BRF:%d\nBRH:%d\n", brFound, brHit)
+	}
+
+	// 行
+	lnHit := 0
+	for _, ln := range f.Lines {
+		_, _ = fmt.Fprintf(ret, "DA:%d,%d\n", ln.LineNumber, ln.Count)
+		if ln.Count > 0 {
+			lnHit++
+		}
+	}
+	_, _ = fmt.Fprintf(ret, "LF:%d\nLH:%d\n", len(f.Lines), lnHit)
+
+	ret.WriteString("end_of_record\n")
+	return ret.String()
+}
@@ -0,0 +1,134 @@
+package lcov
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+)
+
+// TestText 测试 Text 方法
+func TestText(t *testing.T) {
+	a := assert.New(t)
+
+	info := &gcov.CoverageInfo{
+		CurrenWorkingDirectory: "/workdir",
+		Files: []gcov.File{{
+			Filename: "src/main.c",
+			Functions: []gcov.Function{
+				{Name: "main", StartLine: 1, EndLine: 6, ExecutionCount: 1},
+				{Name: "unused", StartLine: 8, EndLine: 10},
+			},
+			Lines: []gcov.Line{
+				{LineNumber: 1, Count: 1},
+				{LineNumber: 2, Count: 5, Branches: []gcov.Branch{
+					{Count: 4, SourceBlockID: 3, DestinationBlockID: 4},
+					{Count: 1, SourceBlockID: 3, DestinationBlockID: 5},
+					{Count: 0, SourceBlockID: 4, DestinationBlockID: 5},
+					{Count: 4, SourceBlockID: 4, DestinationBlockID: 6},
+				}},
+				{LineNumber: 3, Count: 0, Branches: []gcov.Branch{{Count: 0}, {Count: 0}}},
+				{LineNumber: 9, Count: 0},
+			},
+		}},
+	}
+
+	a.Equal(`SF:/workdir/src/main.c
+FN:1,main
+FN:8,unused
+FNDA:1,main
+FNDA:0,unused
+FNF:2
+FNH:1
+BRDA:2,0,0,4
+BRDA:2,0,1,1
+BRDA:2,1,0,0
+BRDA:2,1,1,4
+BRDA:3,0,0,-
+BRDA:3,0,1,-
+BRF:6
+BRH:3
+DA:1,1
+DA:2,5
+DA:3,0
+DA:9,0
+LF:4
+LH:2
+end_of_record
+`, Text(t.Context(), info))
+}
+
+// TestParse 测试 Parse 方法
+func TestParse(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	info, err := Parse(strings.NewReader(`TN:test
+SF:/workdir/src/main.c
+FN:1,6,main
+FNDA:1,main
+FNL:0,8,10
+FNA:0,0,unused
+FNF:2
+FNH:1
+BRDA:2,0,0,4
+BRDA:2,0,1,1
+BRDA:2,1,0,0
+BRDA:2,e1,1,4
+BRDA:3,0,0,-
+BRF:5
+BRH:3
+DA:1,1
+DA:2,5
+DA:3,0
+DA:9,0,abcdef
+LF:4
+LH:2
+end_of_record
+`))
+	r.NoError(err)
+	r.Len(info.Files, 1)
+	f := info.Files[0]
+	a.Equal("/workdir/src/main.c", f.Filename)
+	a.Equal([]gcov.Function{
+		{Name: "main", DemangledName: "main", StartLine: 1, EndLine: 6, ExecutionCount: 1},
+		{Name: "unused", DemangledName: "unused", StartLine: 8, EndLine: 10},
+	}, f.Functions)
+	a.Equal([]gcov.Line{
+		{LineNumber: 1, FunctionName: "main", Count: 1, Branches: []gcov.Branch{}},
+		{LineNumber: 2, FunctionName: "main", Count: 5, Branches: []gcov.Branch{
+			{Count: 4},
+			{Count: 1},
+			{Count: 0, SourceBlockID: 1},
+			{Count: 4, SourceBlockID: 1, Throw: true},
+		}},
+		{LineNumber: 3, FunctionName: "main", UnexecutedBlock: true, Branches: []gcov.Branch{{}}},
+		{LineNumber: 9, FunctionName: "unused", UnexecutedBlock: true, Branches: []gcov.Branch{}},
+	}, f.Lines)
+
+	// 函数名包含逗号
+	info, err = Parse(strings.NewReader(`SF:/workdir/src/map.cpp
+FN:10,std::map<int, int>::find(int const&)
+FN:20,25,add(int, int)
+FNDA:3,std::map<int, int>::find(int const&)
+FNDA:1,add(int, int)
+end_of_record
+`))
+	r.NoError(err)
+	r.Len(info.Files, 1)
+	a.Equal([]gcov.Function{
+		{
+			Name:           "std::map<int, int>::find(int const&)",
+			DemangledName:  "std::map<int, int>::find(int const&)",
+			StartLine:      10,
+			ExecutionCount: 3,
+		},
+		{Name: "add(int, int)", DemangledName: "add(int, int)", StartLine: 20, EndLine: 25, ExecutionCount: 1},
+	}, info.Files[0].Functions)
+
+	_, err = Parse(strings.NewReader("SF:main.c\nDA:x,1\n"))
+	a.Error(err)
+}
@@ -0,0 +1,305 @@
+package lcov
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+)
+
+// ParseFile 解析 LCOV 跟踪文件（ .info ）
+func ParseFile(fileName string) (*gcov.CoverageInfo, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("open tracefile %q error: %w", fileName, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	ret, err := Parse(f)
+	if err != nil {
+		return nil, err
+	}
+	ret.DataFile = fileName
+	return ret, nil
+}
+
+// Parse 解析 LCOV 跟踪文件（ .info ）
+//
+// 每条以 SF 开始、 end_of_record 结束的记录对应一个 gcov.File ，同一源文件的多条记录不会合并。
+// 无法识别的记录会被忽略
+func Parse(r io.Reader) (*gcov.CoverageInfo, error) {
+	ret := &gcov.CoverageInfo{}
+
+	var p *fileParser
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		tag, value, _ := strings.Cut(line, ":")
+		switch tag {
+		case "SF":
+			if p != nil {
+				ret.Files = append(ret.Files, p.File())
+			}
+			p = newFileParser(value)
+			continue
+		case "end_of_record":
+			if p != nil {
+				ret.Files = append(ret.Files, p.File())
+			}
+			p = nil
+			continue
+		}
+		if p == nil {
+			// 不在记录中的 TN 、 VER 等
+			continue
+		}
+		if err := p.ParseRecord(tag, value); err != nil {
+			return ret, fmt.Errorf("parse line %d %q error: %w", lineNo, line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ret, fmt.Errorf("read tracefile error: %w", err)
+	}
+	if p != nil {
+		ret.Files = append(ret.Files, p.File())
+	}
+
+	return ret, nil
+}
+
+// fileParser 单个源文件记录的解析器
+type fileParser struct {
+	file gcov.File
+
+	// 函数下标，键为函数名
+	functions map[string]int
+	// 函数下标，键为 FNL 中的编号
+	functionIndexes map[string]int
+	// 行下标，键为行号
+	lines map[uint32]int
+}
+
+// newFileParser 创建 fileParser
+func newFileParser(fileName string) *fileParser {
+	return &fileParser{
+		file:            gcov.File{Filename: fileName},
+		functions:       map[string]int{},
+		functionIndexes: map[string]int{},
+		lines:           map[uint32]int{},
+	}
+}
+
+// ParseRecord 解析一条记录
+func (p *fileParser) ParseRecord(tag, value string) error {
+	fields := strings.Split(value, ",")
+	switch tag {
+	case "FN":
+		// FN:<start line>[,<end line>],<function name>
+		if len(fields) < 2 {
+			return fmt.Errorf("too few fields")
+		}
+		fn := gcov.Function{}
+		var err error
+		if fn.StartLine, err = parseUint32(fields[0]); err != nil {
+			return err
+		}
+		// 函数名（比如去混淆的 C++ 函数名）可能包含逗号，第二个字段为数字时才视为结束行号
+		if len(fields) >= 3 {
+			if end, err := parseUint32(fields[1]); err == nil {
+				fn.EndLine = end
+				fields = fields[1:]
+			}
+		}
+		fn.Name = strings.Join(fields[1:], ",")
+		fn.DemangledName = fn.Name
+		p.function(fn.Name).StartLine = fn.StartLine
+		p.function(fn.Name).EndLine = fn.EndLine
+	case "FNDA":
+		// FNDA:<execution count>,<function name>
+		if len(fields) < 2 {
+			return fmt.Errorf("too few fields")
+		}
+		count, err := parseUint64(fields[0])
+		if err != nil {
+			return err
+		}
+		p.function(strings.Join(fields[1:], ",")).ExecutionCount = count
+	case "FNL":
+		// FNL:<index>,<start line>[,<end line>]
+		if len(fields) < 2 {
+			return fmt.Errorf("too few fields")
+		}
+		start, err := parseUint32(fields[1])
+		if err != nil {
+			return err
+		}
+		end := uint32(0)
+		if len(fields) >= 3 {
+			if end, err = parseUint32(fields[2]); err != nil {
+				return err
+			}
+		}
+		p.file.Functions = append(p.file.Functions, gcov.Function{StartLine: start, EndLine: end})
+		p.functionIndexes[fields[0]] = len(p.file.Functions) - 1
+	case "FNA":
+		// FNA:<index>,<execution count>,<function name>
+		if len(fields) < 3 {
+			return fmt.Errorf("too few fields")
+		}
+		i, ok := p.functionIndexes[fields[0]]
+		if !ok {
+			return fmt.Errorf("unknown function index %q", fields[0])
+		}
+		count, err := parseUint64(fields[1])
+		if err != nil {
+			return err
+		}
+		name := strings.Join(fields[2:], ",")
+		fn := p.file.Functions[i]
+		if fn.Name != "" {
+			// 同一位置的其它函数（别名）
+			p.file.Functions = append(p.file.Functions, fn)
+			i = len(p.file.Functions) - 1
+		}
+		p.file.Functions[i].Name = name
+		p.file.Functions[i].DemangledName = name
+		p.file.Functions[i].ExecutionCount = count
+		p.functions[name] = i
+	case "DA":
+		// DA:<line number>,<execution count>[,<checksum>]
+		if len(fields) < 2 {
+			return fmt.Errorf("too few fields")
+		}
+		lineNo, err := parseUint32(fields[0])
+		if err != nil {
+			return err
+		}
+		count, err := parseInt64(fields[1])
+		if err != nil {
+			return err
+		}
+		if count < 0 {
+			// 部分工具可能输出负数
+			count = 0
+		}
+		ln := p.line(lineNo)
+		ln.Count += uint64(count)
+		ln.UnexecutedBlock = ln.Count == 0
+	case "BRDA":
+		// BRDA:<line number>,[<exception>]<block>,<branch>,<taken>
+		if len(fields) < 4 {
+			return fmt.Errorf("too few fields")
+		}
+		lineNo, err := parseUint32(fields[0])
+		if err != nil {
+			return err
+		}
+		block := fields[1]
+		throw := strings.HasPrefix(block, "e")
+		block = strings.TrimPrefix(block, "e")
+		blockNo, err := parseUint32(block)
+		if err != nil {
+			return err
+		}
+		count := uint64(0)
+		if taken := fields[len(fields)-1]; taken != "-" {
+			if count, err = parseUint64(taken); err != nil {
+				return err
+			}
+		}
+		ln := p.line(lineNo)
+		ln.Branches = append(ln.Branches, gcov.Branch{
+			Count:         count,
+			Throw:         throw,
+			SourceBlockID: blockNo,
+		})
+	}
+	return nil
+}
+
+// File 返回解析的文件覆盖情况
+func (p *fileParser) File() gcov.File {
+	f := p.file
+	sort.SliceStable(f.Functions, func(i, j int) bool {
+		return f.Functions[i].StartLine < f.Functions[j].StartLine
+	})
+	sort.SliceStable(f.Lines, func(i, j int) bool {
+		return f.Lines[i].LineNumber < f.Lines[j].LineNumber
+	})
+
+	// 根据函数范围确定行所属函数
+	for i := range f.Lines {
+		for _, fn := range f.Functions {
+			if fn.StartLine <= f.Lines[i].LineNumber && f.Lines[i].LineNumber <= fn.EndLine {
+				f.Lines[i].FunctionName = fn.Name
+				break
+			}
+		}
+	}
+	return f
+}
+
+// function 返回指定名字的函数，不存在时创建
+func (p *fileParser) function(name string) *gcov.Function {
+	i, ok := p.functions[name]
+	if !ok {
+		p.file.Functions = append(p.file.Functions, gcov.Function{Name: name, DemangledName: name})
+		i = len(p.file.Functions) - 1
+		p.functions[name] = i
+	}
+	return &p.file.Functions[i]
+}
+
+// line 返回指定行号的行，不存在时创建
+func (p *fileParser) line(lineNo uint32) *gcov.Line {
+	i, ok := p.lines[lineNo]
+	if !ok {
+		p.file.Lines = append(p.file.Lines, gcov.Line{
+			LineNumber:      lineNo,
+			Branches:        []gcov.Branch{},
+			UnexecutedBlock: true,
+		})
+		i = len(p.file.Lines) - 1
+		p.lines[lineNo] = i
+	}
+	return &p.file.Lines[i]
+}
+
+// parseUint32 解析 uint32
+func parseUint32(s string) (uint32, error) {
+	v, err := strconv.ParseUint(strings.TrimSpace(s), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q: %w", s, err)
+	}
+	return uint32(v), nil
+}
+
+// parseUint64 解析 uint64
+func parseUint64(s string) (uint64, error) {
+	v, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q: %w", s, err)
+	}
+	return v, nil
+}
+
+// parseInt64 解析 int64
+func parseInt64(s string) (int64, error) {
+	v, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q: %w", s, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,88 @@
+package sonarqube
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+)
+
+// Options 输出选项
+type Options struct {
+	// 源文件根目录，一般为 SonarQube 项目根目录，位于该目录下的源文件使用相对该目录的路径
+	Root string
+}
+
+// XML 以 SonarQube 通用测试覆盖率 XML 格式输出覆盖情况信息
+func XML(ctx context.Context, opts Options, infos ...*gcov.CoverageInfo) ([]byte, error) {
+	raw, err := xml.MarshalIndent(NewCoverage(ctx, opts, infos...), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal sonarqube xml error: %w", err)
+	}
+	return append(raw, '\n'), nil
+}
+
+// NewCoverage 创建 SonarQube 通用测试覆盖率报告
+//
+// 同一源文件出现在多个覆盖情况信息中（比如被多个目标文件包含的头文件）时，先按 gcov.Merge 合并，
+// 每个源文件只输出一个 file 元素
+func NewCoverage(_ context.Context, opts Options, infos ...*gcov.CoverageInfo) *Coverage {
+	ret := &Coverage{Version: "1", Files: []File{}}
+	info := gcov.Merge(infos...)
+	for _, f := range info.Files {
+		file := File{
+			Path:         filepath.ToSlash(gcov.RelativePath(opts.Root, info.SourcePath(f.Filename))),
+			LinesToCover: make([]LineToCover, len(f.Lines)),
+		}
+		for i, ln := range f.Lines {
+			file.LinesToCover[i] = newLineToCover(&ln)
+		}
+		ret.Files = append(ret.Files, file)
+	}
+	return ret
+}
+
+// Coverage SonarQube 通用测试覆盖率报告
+type Coverage struct {
+	XMLName xml.Name `xml:"coverage"`
+	Version string   `xml:"version,attr"`
+	Files   []File   `xml:"file"`
+}
+
+// File 文件覆盖情况
+type File struct {
+	Path         string        `xml:"path,attr"`
+	LinesToCover []LineToCover `xml:"lineToCover"`
+}
+
+// LineToCover 行覆盖情况
+//
+// 指针类型的字段仅在该行包含分支时输出
+type LineToCover struct {
+	LineNumber      uint32 `xml:"lineNumber,attr"`
+	Covered         bool   `xml:"covered,attr"`
+	BranchesToCover *int   `xml:"branchesToCover,attr,omitempty"`
+	CoveredBranches *int   `xml:"coveredBranches,attr,omitempty"`
+}
+
+// newLineToCover 创建行覆盖情况
+func newLineToCover(ln *gcov.Line) LineToCover {
+	ret := LineToCover{
+		LineNumber: ln.LineNumber,
+		Covered:    ln.Count > 0,
+	}
+	if len(ln.Branches) == 0 {
+		return ret
+	}
+	branches, covered := len(ln.Branches), 0
+	for _, br := range ln.Branches {
+		if br.Count > 0 {
+			covered++
+		}
+	}
+	ret.BranchesToCover = &branches
+	ret.CoveredBranches = &covered
+	return ret
+}
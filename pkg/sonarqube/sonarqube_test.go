@@ -0,0 +1,59 @@
+package sonarqube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+)
+
+// TestXML 测试 XML 方法
+func TestXML(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	info := &gcov.CoverageInfo{
+		CurrenWorkingDirectory: "/workdir",
+		Files: []gcov.File{{
+			Filename: "src/main.c",
+			Lines: []gcov.Line{
+				{LineNumber: 1, Count: 1},
+				{LineNumber: 2, Count: 1, Branches: []gcov.Branch{{Count: 1}, {Count: 0}}},
+				{LineNumber: 3, Count: 0},
+			},
+		}},
+	}
+
+	raw, err := XML(t.Context(), Options{Root: "/workdir"}, info)
+	r.NoError(err)
+	a.Equal(`<coverage version="1">
+  <file path="src/main.c">
+    <lineToCover lineNumber="1" covered="true"></lineToCover>
+    <lineToCover lineNumber="2" covered="true" branchesToCover="2" coveredBranches="1"></lineToCover>
+    <lineToCover lineNumber="3" covered="false"></lineToCover>
+  </file>
+</coverage>
+`, string(raw))
+}
+
+// TestNewCoverage_Merge 测试 NewCoverage 方法合并多个覆盖情况信息中的同一源文件
+func TestNewCoverage_Merge(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	header := func(cwd string, count uint64) *gcov.CoverageInfo {
+		return &gcov.CoverageInfo{
+			CurrenWorkingDirectory: cwd,
+			Files: []gcov.File{{
+				Filename: "../include/util.h",
+				Lines:    []gcov.Line{{LineNumber: 1, Count: count}, {LineNumber: 2}},
+			}},
+		}
+	}
+	c := NewCoverage(t.Context(), Options{Root: "/workdir"}, header("/workdir/a", 0), header("/workdir/b", 2))
+	r.Len(c.Files, 1)
+	a.Equal("include/util.h", c.Files[0].Path)
+	a.Equal([]LineToCover{{LineNumber: 1, Covered: true}, {LineNumber: 2}}, c.Files[0].LinesToCover)
+}
@@ -45,6 +45,12 @@ for img in ${gcc_images[*]}; do
   echo "GCC: ${img}"
   echo "Output dir: ${output_dir}"
 
+  # GCC 15 起输出质路径覆盖情况
+  gcov_args=""
+  if [ "${img##*:}" -ge 15 ]; then
+    gcov_args="--prime-paths"
+  fi
+
   rm -rf "${output_root}/${output_dir}"
   mkdir -p "${output_root}/${output_dir}"
   mkdir -p "${output_root}/${output_dir}/intermediate"
@@ -68,5 +74,5 @@ for img in ${gcc_images[*]}; do
     -v "${code_root}/src:/workdir/src:ro" \
     --workdir "/workdir" \
     "${img}" \
-    bash -c "cd intermediate && find .. -name '*.gcno' -exec gcov -ib {} \; && cd ../human_readable && find .. -name '*.gcno' -exec gcov -bc {} \;"
+    bash -c "cd intermediate && find .. -name '*.gcno' -exec gcov -ib ${gcov_args} {} \; && cd ../human_readable && find .. -name '*.gcno' -exec gcov -bc ${gcov_args} {} \;"
 done
@@ -0,0 +1,110 @@
+package resolvecov
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yhlooo/gcovgo/pkg/gcov"
+	"github.com/yhlooo/gcovgo/samples/gcovdata"
+)
+
+// TestResolvePrimePaths 测试解算质路径覆盖率，与 gcov --prime-paths 的 JSON 中间格式输出对比
+//
+// 测试数据由 gen-gcov-data.sh 使用 gcc 15 以 -fpath-coverage 编译生成，没有质路径覆盖数据时跳过
+func TestResolvePrimePaths(t *testing.T) {
+	found := false
+	for _, item := range gcovdata.Data {
+		if item.IntermediateOutputFile == "" || !item.IntermediaJSON {
+			continue
+		}
+		expected, err := gcovdata.FS.ReadFile(item.IntermediateOutputFile)
+		require.NoError(t, err)
+		expectedInfos, err := gcov.ParseJSON(bytes.NewReader(expected))
+		require.NoError(t, err)
+		if !hasPrimePaths(expectedInfos) {
+			continue
+		}
+		found = true
+		t.Run(item.NoteFile, testResolveSinglePrimePaths(item, expectedInfos))
+	}
+	if !found {
+		t.Skip("no sample with prime path coverage, regenerate samples with gcc 15 by gen-gcov-data.sh")
+	}
+}
+
+// testResolveSinglePrimePaths 测试解算单个 note 和 data 文件的质路径覆盖率
+func testResolveSinglePrimePaths(item gcovdata.Item, expectedInfos []*gcov.CoverageInfo) func(t *testing.T) {
+	return func(t *testing.T) {
+		r := require.New(t)
+		a := assert.New(t)
+
+		// 打开测试文件
+		noteFile, err := gcovdata.FS.Open(item.NoteFile)
+		r.NoError(err)
+		defer func() { _ = noteFile.Close() }()
+		var dataFile io.ReadCloser
+		if item.DataFile != "" {
+			dataFile, err = gcovdata.FS.Open(item.DataFile)
+			r.NoError(err)
+			defer func() { _ = dataFile.Close() }()
+		}
+
+		// 测试
+		info, err := gcov.ResolveBinary(noteFile, dataFile)
+		r.NoError(err)
+		// 经 JSON 中间格式转换后与 gcov --prime-paths 输出对比
+		ctx := gcov.ContextWithPrimePathsMode(t.Context(), gcov.PrimePathsSummary)
+		raw, err := info.IntermediateJSON(ctx)
+		r.NoError(err)
+		actualInfos, err := gcov.ParseJSON(bytes.NewReader(raw))
+		r.NoError(err)
+
+		// 校验结果
+		a.Equal(primePaths(expectedInfos), primePaths(actualInfos))
+	}
+}
+
+// functionPrimePaths 函数质路径覆盖情况
+type functionPrimePaths struct {
+	Total   uint32
+	Covered uint32
+	Paths   []gcov.PrimePath
+}
+
+// primePaths 返回按文件名和函数名索引的质路径覆盖情况
+func primePaths(infos []*gcov.CoverageInfo) map[string]map[string]functionPrimePaths {
+	ret := map[string]map[string]functionPrimePaths{}
+	for _, info := range infos {
+		for _, f := range info.Files {
+			if ret[f.Filename] == nil {
+				ret[f.Filename] = map[string]functionPrimePaths{}
+			}
+			for _, fn := range f.Functions {
+				ret[f.Filename][fn.Name] = functionPrimePaths{
+					Total:   fn.TotalPrimePaths,
+					Covered: fn.CoveredPrimePaths,
+					Paths:   fn.PrimePaths,
+				}
+			}
+		}
+	}
+	return ret
+}
+
+// hasPrimePaths 判断是否有质路径覆盖数据
+func hasPrimePaths(infos []*gcov.CoverageInfo) bool {
+	for _, info := range infos {
+		for _, f := range info.Files {
+			for _, fn := range f.Functions {
+				if fn.TotalPrimePaths > 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}